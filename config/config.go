@@ -3,6 +3,7 @@ package config
 import (
 	"github.com/caarlos0/env/v11"
 	"sync"
+	"time"
 )
 
 var (
@@ -15,6 +16,78 @@ type Config struct {
 	APIURL    string `env:"API_URL"`
 	AuthToken string `env:"AUTH_TOKEN"`
 	Transport string `env:"TRANSPORT"`
+
+	Environment string `env:"ENVIRONMENT" envDefault:"production"`
+
+	Backend       string `env:"BACKEND" envDefault:"rest"`
+	MemoryFixture string `env:"MEMORY_FIXTURE" envDefault:"fixtures/store.json"`
+
+	BulkAddConcurrency int  `env:"BULK_ADD_CONCURRENCY" envDefault:"4"`
+	BulkAddRollback    bool `env:"BULK_ADD_ROLLBACK"`
+	CartSnapshotLimit  int  `env:"CART_SNAPSHOT_LIMIT" envDefault:"20"`
+
+	PromotionsFile string `env:"PROMOTIONS_FILE"`
+	CheckoutFile   string `env:"CHECKOUT_RULES_FILE"`
+	// WishlistFile holds the wishlists of backends without a wishlist API
+	// (one answering 404, 405 or 501 for it). They are kept per user: under
+	// WishlistUser when set, or else under the user id the backend reports
+	// for the cart. Wishlists earlier versions kept under a hash of
+	// AUTH_TOKEN move to that key on first use.
+	WishlistFile string `env:"WISHLIST_FILE"`
+	WishlistUser string `env:"WISHLIST_USER"`
+
+	StoreCurrency     string `env:"STORE_CURRENCY" envDefault:"USD"`
+	DisplayCurrency   string `env:"DISPLAY_CURRENCY"`
+	ExchangeRatesFile string `env:"EXCHANGE_RATES_FILE"`
+
+	HTTPConnectTimeout time.Duration `env:"HTTP_CONNECT_TIMEOUT" envDefault:"5s"`
+	HTTPHeaderTimeout  time.Duration `env:"HTTP_HEADER_TIMEOUT" envDefault:"10s"`
+	HTTPTimeout        time.Duration `env:"HTTP_TIMEOUT" envDefault:"30s"`
+	ToolCallTimeout    time.Duration `env:"TOOL_CALL_TIMEOUT" envDefault:"60s"`
+
+	TLSCAFile             string        `env:"TLS_CA_FILE"`
+	TLSCertFile           string        `env:"TLS_CERT_FILE"`
+	TLSKeyFile            string        `env:"TLS_KEY_FILE"`
+	TLSMinVersion         string        `env:"TLS_MIN_VERSION" envDefault:"1.2"`
+	TLSInsecureSkipVerify bool          `env:"TLS_INSECURE_SKIP_VERIFY"`
+	TLSReloadInterval     time.Duration `env:"TLS_RELOAD_INTERVAL" envDefault:"30s"`
+
+	ProxyURL string   `env:"PROXY_URL"`
+	NoProxy  []string `env:"NO_PROXY_HOSTS" envSeparator:","`
+
+	RetryMaxAttempts    int           `env:"RETRY_MAX_ATTEMPTS" envDefault:"3"`
+	RetryInitialBackoff time.Duration `env:"RETRY_INITIAL_BACKOFF" envDefault:"200ms"`
+	RetryMaxBackoff     time.Duration `env:"RETRY_MAX_BACKOFF" envDefault:"5s"`
+	RetryMultiplier     float64       `env:"RETRY_MULTIPLIER" envDefault:"2"`
+
+	BreakerFailureThreshold int           `env:"BREAKER_FAILURE_THRESHOLD" envDefault:"5"`
+	BreakerOpenTimeout      time.Duration `env:"BREAKER_OPEN_TIMEOUT" envDefault:"30s"`
+	BreakerHalfOpenMaxCalls int           `env:"BREAKER_HALF_OPEN_MAX_CALLS" envDefault:"1"`
+
+	ClientStatsInterval time.Duration `env:"CLIENT_STATS_INTERVAL" envDefault:"5m"`
+
+	StrictDecoding bool `env:"STRICT_DECODING"`
+
+	CacheMaxEntries int           `env:"CACHE_MAX_ENTRIES" envDefault:"256"`
+	CacheTTL        time.Duration `env:"CACHE_TTL" envDefault:"60s"`
+
+	RateLimitDefault      float64            `env:"RATE_LIMIT_DEFAULT"`
+	RateLimitDefaultBurst int                `env:"RATE_LIMIT_DEFAULT_BURST" envDefault:"1"`
+	RateLimits            map[string]float64 `env:"RATE_LIMITS" envSeparator:"," envKeyValSeparator:":"`
+	RateLimitBursts       map[string]int     `env:"RATE_LIMIT_BURSTS" envSeparator:"," envKeyValSeparator:":"`
+
+	OpenAPISpec       string            `env:"OPENAPI_SPEC"`
+	OpenAPIInclude    []string          `env:"OPENAPI_INCLUDE" envSeparator:","`
+	OpenAPIExclude    []string          `env:"OPENAPI_EXCLUDE" envSeparator:","`
+	OpenAPIRenames    map[string]string `env:"OPENAPI_RENAMES" envSeparator:"," envKeyValSeparator:":"`
+	OpenAPIToolPrefix string            `env:"OPENAPI_TOOL_PREFIX"`
+
+	ServerTitle            string   `env:"SERVER_TITLE" envDefault:"Cartopher Shopping Copilot"`
+	ServerDescription      string   `env:"SERVER_DESCRIPTION" envDefault:"Browse the Cartopher catalog, manage your cart and place orders."`
+	ServerWebsiteURL       string   `env:"SERVER_WEBSITE_URL"`
+	ServerIcons            []string `env:"SERVER_ICONS" envSeparator:","`
+	ServerInstructions     string   `env:"SERVER_INSTRUCTIONS"`
+	ServerInstructionsFile string   `env:"SERVER_INSTRUCTIONS_FILE"`
 }
 
 func GetConfig() (*Config, error) {
@@ -0,0 +1,91 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"time"
+)
+
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrInvalid     = errors.New("invalid request")
+	ErrConflict    = errors.New("conflict")
+	ErrRejected    = errors.New("request rejected by store")
+	ErrUnsupported = errors.New("not supported by this backend")
+)
+
+type ProductQuery struct {
+	Limit  int
+	Offset int
+}
+
+type SearchQuery struct {
+	Query      string
+	Limit      int
+	Offset     int
+	MinPrice   float64
+	MaxPrice   float64
+	CategoryID string
+}
+
+// OrderQuery filters the order history. Status and the zero times match every
+// order; To is exclusive.
+type OrderQuery struct {
+	Limit  int
+	Offset int
+	Status string
+	From   time.Time
+	To     time.Time
+}
+
+type PlaceOrderRequest struct {
+	IdempotencyKey string
+	CouponCodes    []string
+}
+
+type Catalog interface {
+	ListProducts(ctx context.Context, q ProductQuery) ([]models.Product, error)
+	SearchProducts(ctx context.Context, q SearchQuery) ([]models.Product, error)
+	GetProduct(ctx context.Context, id int) (*models.Product, error)
+	GetProductBySKU(ctx context.Context, sku string) (*models.Product, error)
+}
+
+type Cart interface {
+	GetCart(ctx context.Context) (*models.Cart, error)
+	AddToCart(ctx context.Context, productID, quantity int) (*models.Cart, error)
+	UpdateCartItem(ctx context.Context, itemID, quantity int) (*models.Cart, error)
+	RemoveCartItem(ctx context.Context, itemID int) (*models.Cart, error)
+	ClearCart(ctx context.Context) (*models.Cart, error)
+}
+
+type Orders interface {
+	PlaceOrder(ctx context.Context, req PlaceOrderRequest) (*models.Order, error)
+	// ListOrders returns the user's orders, newest first.
+	ListOrders(ctx context.Context, q OrderQuery) ([]models.Order, error)
+	GetOrder(ctx context.Context, id int) (*models.Order, error)
+}
+
+// Promotions is implemented by every backend; those without coupon support
+// return ErrUnsupported.
+type Promotions interface {
+	ListPromotions(ctx context.Context) ([]models.Promotion, error)
+	ApplyCoupon(ctx context.Context, code string) (*models.Cart, error)
+	RemoveCoupon(ctx context.Context, code string) (*models.Cart, error)
+}
+
+// Wishlist is implemented by every backend; those without a wishlist API
+// return ErrUnsupported.
+type Wishlist interface {
+	GetWishlist(ctx context.Context) (*models.Wishlist, error)
+	AddToWishlist(ctx context.Context, productID int) (*models.Wishlist, error)
+	RemoveFromWishlist(ctx context.Context, productID int) (*models.Wishlist, error)
+}
+
+type Backend interface {
+	Catalog
+	Cart
+	Orders
+	Promotions
+	Wishlist
+}
@@ -0,0 +1,428 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Fixture struct {
+	UserID     int               `json:"user_id"`
+	Categories []models.Category `json:"categories"`
+	Products   []models.Product  `json:"products"`
+	Orders     []models.Order    `json:"orders,omitempty"`
+}
+
+type Backend struct {
+	mu          sync.Mutex
+	products    []models.Product
+	cart        models.Cart
+	orders      []models.Order
+	nextItemID  int
+	nextOrderID int
+	idempotency map[string]models.Order
+}
+
+func (b *Backend) ListProducts(_ context.Context, q backend.ProductQuery) ([]models.Product, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	active := make([]models.Product, 0, len(b.products))
+	for _, p := range b.products {
+		if p.IsActive {
+			active = append(active, p)
+		}
+	}
+	return paginate(active, q.Limit, q.Offset), nil
+}
+
+func (b *Backend) SearchProducts(_ context.Context, q backend.SearchQuery) ([]models.Product, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	needle := strings.ToLower(strings.TrimSpace(q.Query))
+	matches := make([]models.Product, 0)
+	for _, p := range b.products {
+		if !p.IsActive {
+			continue
+		}
+		if needle != "" && !strings.Contains(strings.ToLower(p.Name), needle) &&
+			!strings.Contains(strings.ToLower(p.Description), needle) &&
+			!strings.EqualFold(p.Sku, needle) {
+			continue
+		}
+		if q.MinPrice > 0 && p.Price < q.MinPrice {
+			continue
+		}
+		if q.MaxPrice > 0 && p.Price > q.MaxPrice {
+			continue
+		}
+		if q.CategoryID != "" && q.CategoryID != strconv.Itoa(p.CategoryId) && !strings.EqualFold(q.CategoryID, p.Category.Name) {
+			continue
+		}
+		matches = append(matches, p)
+	}
+	return paginate(matches, q.Limit, q.Offset), nil
+}
+
+func (b *Backend) GetProduct(_ context.Context, id int) (*models.Product, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	p, ok := b.product(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: product %d", backend.ErrNotFound, id)
+	}
+	product := *p
+	return &product, nil
+}
+
+func (b *Backend) GetProductBySKU(_ context.Context, sku string) (*models.Product, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, p := range b.products {
+		if strings.EqualFold(p.Sku, sku) {
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: product with SKU %s", backend.ErrNotFound, sku)
+}
+
+func (b *Backend) GetCart(_ context.Context) (*models.Cart, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.snapshot(), nil
+}
+
+func (b *Backend) AddToCart(_ context.Context, productID, quantity int) (*models.Cart, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if quantity <= 0 {
+		return nil, fmt.Errorf("%w: quantity must be positive", backend.ErrInvalid)
+	}
+
+	p, ok := b.product(productID)
+	if !ok {
+		return nil, fmt.Errorf("%w: product %d", backend.ErrNotFound, productID)
+	}
+	if !p.IsActive {
+		return nil, fmt.Errorf("%w: product %d is not available", backend.ErrConflict, productID)
+	}
+
+	now := time.Now()
+	idx := b.cartIndex(productID)
+	inCart := 0
+	if idx >= 0 {
+		inCart = b.cart.CartItems[idx].Quantity
+	}
+	if inCart+quantity > p.Stock {
+		return nil, fmt.Errorf("%w: only %d of product %d in stock", backend.ErrConflict, p.Stock, productID)
+	}
+
+	if idx >= 0 {
+		b.cart.CartItems[idx].Quantity += quantity
+		b.cart.CartItems[idx].UpdatedAt = now
+	} else {
+		b.nextItemID++
+		b.cart.CartItems = append(b.cart.CartItems, models.CartItem{
+			Id:        b.nextItemID,
+			Product:   *p,
+			Quantity:  quantity,
+			Price:     p.Price,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	b.recalculate(now)
+	return b.snapshot(), nil
+}
+
+func (b *Backend) UpdateCartItem(_ context.Context, itemID, quantity int) (*models.Cart, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if quantity <= 0 {
+		return nil, fmt.Errorf("%w: quantity must be positive", backend.ErrInvalid)
+	}
+
+	idx := b.itemIndex(itemID)
+	if idx < 0 {
+		return nil, fmt.Errorf("%w: cart item %d", backend.ErrNotFound, itemID)
+	}
+
+	item := &b.cart.CartItems[idx]
+	p, ok := b.product(item.Product.Id)
+	if !ok || !p.IsActive {
+		return nil, fmt.Errorf("%w: product %d is not available", backend.ErrConflict, item.Product.Id)
+	}
+	if quantity > p.Stock {
+		return nil, fmt.Errorf("%w: only %d of product %d in stock", backend.ErrConflict, p.Stock, p.Id)
+	}
+
+	now := time.Now()
+	item.Quantity = quantity
+	item.UpdatedAt = now
+
+	b.recalculate(now)
+	return b.snapshot(), nil
+}
+
+func (b *Backend) RemoveCartItem(_ context.Context, itemID int) (*models.Cart, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	idx := b.itemIndex(itemID)
+	if idx < 0 {
+		return nil, fmt.Errorf("%w: cart item %d", backend.ErrNotFound, itemID)
+	}
+
+	b.cart.CartItems = slices.Delete(b.cart.CartItems, idx, idx+1)
+	b.recalculate(time.Now())
+	return b.snapshot(), nil
+}
+
+func (b *Backend) ClearCart(_ context.Context) (*models.Cart, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.cart.CartItems = nil
+	b.recalculate(time.Now())
+	return b.snapshot(), nil
+}
+
+func (b *Backend) PlaceOrder(_ context.Context, req backend.PlaceOrderRequest) (*models.Order, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if order, ok := b.idempotency[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		return &order, nil
+	}
+
+	if len(b.cart.CartItems) == 0 {
+		return nil, fmt.Errorf("%w: cart is empty", backend.ErrInvalid)
+	}
+
+	for _, item := range b.cart.CartItems {
+		p, ok := b.product(item.Product.Id)
+		if !ok || !p.IsActive {
+			return nil, fmt.Errorf("%w: product %d is no longer available", backend.ErrConflict, item.Product.Id)
+		}
+		if item.Quantity > p.Stock {
+			return nil, fmt.Errorf("%w: only %d of product %d in stock", backend.ErrConflict, p.Stock, p.Id)
+		}
+	}
+
+	for _, item := range b.cart.CartItems {
+		p, _ := b.product(item.Product.Id)
+		p.Stock -= item.Quantity
+	}
+
+	now := time.Now()
+	items := make([]models.OrderItem, 0, len(b.cart.CartItems))
+	for i, item := range b.cart.CartItems {
+		items = append(items, models.OrderItem{
+			Id:       i + 1,
+			Product:  item.Product,
+			Quantity: item.Quantity,
+			Price:    item.Product.Price,
+			Subtotal: item.Subtotal,
+		})
+	}
+
+	b.nextOrderID++
+	order := models.Order{
+		Id:          b.nextOrderID,
+		UserId:      b.cart.UserId,
+		Status:      models.OrderPending,
+		Subtotal:    b.cart.Total,
+		Total:       b.cart.Total,
+		CouponCodes: slices.Clone(req.CouponCodes),
+		OrderItems:  items,
+		StatusHistory: []models.OrderStatusEvent{
+			{Status: models.OrderPending, Note: "Order placed", CreatedAt: now},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	b.orders = append(b.orders, order)
+	if req.IdempotencyKey != "" {
+		b.idempotency[req.IdempotencyKey] = order
+	}
+
+	b.cart.CartItems = nil
+	b.recalculate(now)
+
+	return &order, nil
+}
+
+func (b *Backend) ListOrders(_ context.Context, q backend.OrderQuery) ([]models.Order, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	matches := make([]models.Order, 0, len(b.orders))
+	for _, order := range slices.Backward(b.orders) {
+		if q.Status != "" && !strings.EqualFold(order.Status, q.Status) {
+			continue
+		}
+		if !q.From.IsZero() && order.CreatedAt.Before(q.From) {
+			continue
+		}
+		if !q.To.IsZero() && !order.CreatedAt.Before(q.To) {
+			continue
+		}
+		matches = append(matches, order)
+	}
+	return paginate(matches, q.Limit, q.Offset), nil
+}
+
+func (b *Backend) GetOrder(_ context.Context, id int) (*models.Order, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	idx := slices.IndexFunc(b.orders, func(order models.Order) bool { return order.Id == id })
+	if idx < 0 {
+		return nil, fmt.Errorf("%w: order %d", backend.ErrNotFound, id)
+	}
+	order := b.orders[idx]
+	return &order, nil
+}
+
+func (b *Backend) ListPromotions(context.Context) ([]models.Promotion, error) {
+	return nil, fmt.Errorf("%w: promotions", backend.ErrUnsupported)
+}
+
+func (b *Backend) ApplyCoupon(context.Context, string) (*models.Cart, error) {
+	return nil, fmt.Errorf("%w: coupons", backend.ErrUnsupported)
+}
+
+func (b *Backend) RemoveCoupon(context.Context, string) (*models.Cart, error) {
+	return nil, fmt.Errorf("%w: coupons", backend.ErrUnsupported)
+}
+
+func (b *Backend) GetWishlist(context.Context) (*models.Wishlist, error) {
+	return nil, fmt.Errorf("%w: wishlist", backend.ErrUnsupported)
+}
+
+func (b *Backend) AddToWishlist(context.Context, int) (*models.Wishlist, error) {
+	return nil, fmt.Errorf("%w: wishlist", backend.ErrUnsupported)
+}
+
+func (b *Backend) RemoveFromWishlist(context.Context, int) (*models.Wishlist, error) {
+	return nil, fmt.Errorf("%w: wishlist", backend.ErrUnsupported)
+}
+
+func (b *Backend) product(id int) (*models.Product, bool) {
+	for i := range b.products {
+		if b.products[i].Id == id {
+			return &b.products[i], true
+		}
+	}
+	return nil, false
+}
+
+func (b *Backend) cartIndex(productID int) int {
+	return slices.IndexFunc(b.cart.CartItems, func(item models.CartItem) bool {
+		return item.Product.Id == productID
+	})
+}
+
+func (b *Backend) itemIndex(itemID int) int {
+	return slices.IndexFunc(b.cart.CartItems, func(item models.CartItem) bool {
+		return item.Id == itemID
+	})
+}
+
+// recalculate refreshes line subtotals from current catalog prices, the way
+// the REST backend reports them.
+func (b *Backend) recalculate(now time.Time) {
+	total := 0.0
+	for i := range b.cart.CartItems {
+		item := &b.cart.CartItems[i]
+		if p, ok := b.product(item.Product.Id); ok {
+			item.Product = *p
+		}
+		item.Subtotal = item.Product.Price * float64(item.Quantity)
+		total += item.Subtotal
+	}
+	b.cart.Total = total
+	b.cart.UpdatedAt = now
+}
+
+func (b *Backend) snapshot() *models.Cart {
+	cart := b.cart
+	cart.CartItems = slices.Clone(b.cart.CartItems)
+	return &cart
+}
+
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[max(offset, 0):]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+func Load(path string) (*Backend, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(bs, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+
+	return New(fixture), nil
+}
+
+func New(fixture Fixture) *Backend {
+	categories := make(map[int]models.Category, len(fixture.Categories))
+	for _, c := range fixture.Categories {
+		categories[c.Id] = c
+	}
+
+	products := slices.Clone(fixture.Products)
+	for i := range products {
+		if c, ok := categories[products[i].CategoryId]; ok {
+			products[i].Category = c
+		}
+	}
+
+	// Orders are kept oldest first, the order PlaceOrder appends them in.
+	orders := slices.Clone(fixture.Orders)
+	slices.SortStableFunc(orders, func(a, b models.Order) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	nextOrderID := 0
+	for _, order := range orders {
+		nextOrderID = max(nextOrderID, order.Id)
+	}
+
+	now := time.Now()
+	return &Backend{
+		products:    products,
+		orders:      orders,
+		nextOrderID: nextOrderID,
+		cart: models.Cart{
+			Id:        1,
+			UserId:    fixture.UserID,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		idempotency: make(map[string]models.Order),
+	}
+}
@@ -0,0 +1,318 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/client"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Backend struct {
+	restClient *client.RestClient
+}
+
+func (b *Backend) ListProducts(ctx context.Context, q backend.ProductQuery) ([]models.Product, error) {
+	params := map[string]string{
+		"limit":  strconv.Itoa(q.Limit),
+		"offset": strconv.Itoa(q.Offset),
+	}
+
+	response, err := b.restClient.Get(ctx, "/products", params)
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	return decode[[]models.Product](ctx, b.restClient, response)
+}
+
+func (b *Backend) SearchProducts(ctx context.Context, q backend.SearchQuery) ([]models.Product, error) {
+	params := map[string]string{
+		"limit":  strconv.Itoa(q.Limit),
+		"offset": strconv.Itoa(q.Offset),
+		"q":      q.Query,
+	}
+
+	if q.MinPrice > 0 {
+		params["min_price"] = fmt.Sprintf("%.2f", q.MinPrice)
+	}
+
+	if q.MaxPrice > 0 {
+		params["max_price"] = fmt.Sprintf("%.2f", q.MaxPrice)
+	}
+
+	if q.CategoryID != "" {
+		params["category"] = q.CategoryID
+	}
+
+	response, err := b.restClient.Get(ctx, "/search", params)
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	return decode[[]models.Product](ctx, b.restClient, response)
+}
+
+func (b *Backend) GetProduct(ctx context.Context, id int) (*models.Product, error) {
+	response, err := b.restClient.Get(ctx, fmt.Sprintf("/products/%d", id), nil)
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	product, err := decode[models.Product](ctx, b.restClient, response)
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// GetProductBySKU searches the catalog for the SKU and keeps only an exact,
+// case-insensitive match, since the API has no SKU lookup endpoint.
+func (b *Backend) GetProductBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	products, err := b.SearchProducts(ctx, backend.SearchQuery{Query: sku, Limit: 50})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range products {
+		if strings.EqualFold(p.Sku, sku) {
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: product with SKU %s", backend.ErrNotFound, sku)
+}
+
+func (b *Backend) GetCart(ctx context.Context) (*models.Cart, error) {
+	response, err := b.restClient.WithToken().Get(ctx, "/cart", nil)
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	cart, err := decode[models.Cart](ctx, b.restClient, response)
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+func (b *Backend) AddToCart(ctx context.Context, productID, quantity int) (*models.Cart, error) {
+	body := map[string]any{
+		"product_id": productID,
+		"quantity":   quantity,
+	}
+
+	response, err := b.restClient.WithToken().Post(ctx, "/cart/items", body)
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	cart, err := decode[models.Cart](ctx, b.restClient, response)
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// The cart line endpoints do not consistently return the updated cart, so
+// each mutation is followed by a fresh read.
+func (b *Backend) UpdateCartItem(ctx context.Context, itemID, quantity int) (*models.Cart, error) {
+	body := map[string]any{
+		"quantity": quantity,
+	}
+
+	if _, err := b.restClient.WithToken().Put(ctx, fmt.Sprintf("/cart/items/%d", itemID), body); err != nil {
+		return nil, translate(err)
+	}
+	return b.GetCart(ctx)
+}
+
+func (b *Backend) RemoveCartItem(ctx context.Context, itemID int) (*models.Cart, error) {
+	if _, err := b.restClient.WithToken().Delete(ctx, fmt.Sprintf("/cart/items/%d", itemID)); err != nil {
+		return nil, translate(err)
+	}
+	return b.GetCart(ctx)
+}
+
+func (b *Backend) ClearCart(ctx context.Context) (*models.Cart, error) {
+	if _, err := b.restClient.WithToken().Delete(ctx, "/cart"); err != nil {
+		return nil, translate(err)
+	}
+	return b.GetCart(ctx)
+}
+
+func (b *Backend) PlaceOrder(ctx context.Context, req backend.PlaceOrderRequest) (*models.Order, error) {
+	key := req.IdempotencyKey
+	if key == "" {
+		key = client.NewIdempotencyKey()
+	}
+
+	var body any
+	if len(req.CouponCodes) > 0 {
+		body = map[string]any{
+			"coupon_codes": req.CouponCodes,
+		}
+	}
+
+	response, err := b.restClient.WithToken().WithIdempotencyKey(key).Post(ctx, "/orders", body)
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	order, err := decode[models.Order](ctx, b.restClient, response)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (b *Backend) ListOrders(ctx context.Context, q backend.OrderQuery) ([]models.Order, error) {
+	params := map[string]string{
+		"limit":  strconv.Itoa(q.Limit),
+		"offset": strconv.Itoa(q.Offset),
+	}
+
+	if q.Status != "" {
+		params["status"] = q.Status
+	}
+
+	if !q.From.IsZero() {
+		params["from"] = q.From.UTC().Format(time.RFC3339)
+	}
+
+	if !q.To.IsZero() {
+		params["to"] = q.To.UTC().Format(time.RFC3339)
+	}
+
+	response, err := b.restClient.WithToken().Get(ctx, "/orders", params)
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	return decode[[]models.Order](ctx, b.restClient, response)
+}
+
+func (b *Backend) GetOrder(ctx context.Context, id int) (*models.Order, error) {
+	response, err := b.restClient.WithToken().Get(ctx, fmt.Sprintf("/orders/%d", id), nil)
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	order, err := decode[models.Order](ctx, b.restClient, response)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (b *Backend) ListPromotions(ctx context.Context) ([]models.Promotion, error) {
+	response, err := b.restClient.WithToken().Get(ctx, "/promotions", nil)
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	return decode[[]models.Promotion](ctx, b.restClient, response)
+}
+
+func (b *Backend) ApplyCoupon(ctx context.Context, code string) (*models.Cart, error) {
+	body := map[string]any{
+		"code": code,
+	}
+
+	if _, err := b.restClient.WithToken().Post(ctx, "/cart/coupons", body); err != nil {
+		return nil, translate(err)
+	}
+	return b.GetCart(ctx)
+}
+
+func (b *Backend) RemoveCoupon(ctx context.Context, code string) (*models.Cart, error) {
+	if _, err := b.restClient.WithToken().Delete(ctx, "/cart/coupons/"+url.PathEscape(code)); err != nil {
+		return nil, translate(err)
+	}
+	return b.GetCart(ctx)
+}
+
+// GetWishlist reports ErrUnsupported when the API has no wishlist: besides
+// 405 and 501, an API without the route answers 404 for the collection.
+func (b *Backend) GetWishlist(ctx context.Context) (*models.Wishlist, error) {
+	response, err := b.restClient.WithToken().Get(ctx, "/wishlist", nil)
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %w", backend.ErrUnsupported, err)
+	}
+	if err != nil {
+		return nil, translate(err)
+	}
+
+	wishlist, err := decode[models.Wishlist](ctx, b.restClient, response)
+	if err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (b *Backend) AddToWishlist(ctx context.Context, productID int) (*models.Wishlist, error) {
+	body := map[string]any{
+		"product_id": productID,
+	}
+
+	if _, err := b.restClient.WithToken().Post(ctx, "/wishlist/items", body); err != nil {
+		return nil, translate(err)
+	}
+	return b.GetWishlist(ctx)
+}
+
+func (b *Backend) RemoveFromWishlist(ctx context.Context, productID int) (*models.Wishlist, error) {
+	if _, err := b.restClient.WithToken().Delete(ctx, fmt.Sprintf("/wishlist/items/%d", productID)); err != nil {
+		return nil, translate(err)
+	}
+	return b.GetWishlist(ctx)
+}
+
+// translate keeps the API error for the caller while also marking it with
+// the backend error that matches its status.
+func translate(err error) error {
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %w", backend.ErrNotFound, err)
+	case http.StatusConflict:
+		return fmt.Errorf("%w: %w", backend.ErrConflict, err)
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return fmt.Errorf("%w: %w", backend.ErrInvalid, err)
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return fmt.Errorf("%w: %w", backend.ErrUnsupported, err)
+	default:
+		return err
+	}
+}
+
+// decode unwraps the response envelope and turns an error reported inside a
+// successful response into a backend error.
+func decode[T any](ctx context.Context, restClient *client.RestClient, response []byte) (T, error) {
+	var zero T
+
+	envelope, err := client.DecodeEnvelope[T](ctx, restClient, response)
+	if err != nil {
+		return zero, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if envelope.Error != "" {
+		return zero, fmt.Errorf("%w: %s", backend.ErrRejected, envelope.Error)
+	}
+
+	return envelope.Data, nil
+}
+
+func New(restClient *client.RestClient) *Backend {
+	return &Backend{restClient: restClient}
+}
@@ -0,0 +1,73 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// Matcher decides whether a recorded interaction answers an outgoing request.
+// The request has already been scrubbed, so it can be compared field by field.
+type Matcher func(req RecordedRequest, recorded RecordedRequest) bool
+
+func MatchMethodURL(req, recorded RecordedRequest) bool {
+	return req.Method == recorded.Method && sameURL(req.URL, recorded.URL, true)
+}
+
+func MatchMethodPath(req, recorded RecordedRequest) bool {
+	return req.Method == recorded.Method && sameURL(req.URL, recorded.URL, false)
+}
+
+func MatchMethodURLBody(req, recorded RecordedRequest) bool {
+	return MatchMethodURL(req, recorded) && sameBody(req.Body, recorded.Body)
+}
+
+func MatcherByName(name string) (Matcher, error) {
+	switch name {
+	case "", "method_url":
+		return MatchMethodURL, nil
+	case "method_path":
+		return MatchMethodPath, nil
+	case "method_url_body":
+		return MatchMethodURLBody, nil
+	default:
+		return nil, fmt.Errorf("unknown cassette matcher %q", name)
+	}
+}
+
+// sameURL compares parsed URLs so that query parameter order, which Go's
+// map-backed query encoding does not guarantee, never breaks a match.
+func sameURL(a, b string, withQuery bool) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+
+	if ua.Scheme != ub.Scheme || ua.Host != ub.Host || ua.Path != ub.Path {
+		return false
+	}
+	return !withQuery || ua.Query().Encode() == ub.Query().Encode()
+}
+
+func sameBody(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, []byte(a)) != nil || json.Compact(&cb, []byte(b)) != nil {
+		return false
+	}
+
+	var va, vb any
+	if json.Unmarshal(ca.Bytes(), &va) != nil || json.Unmarshal(cb.Bytes(), &vb) != nil {
+		return false
+	}
+
+	// Re-encoding sorts object keys, making the comparison order-insensitive.
+	ea, _ := json.Marshal(va)
+	eb, _ := json.Marshal(vb)
+	return bytes.Equal(ea, eb)
+}
@@ -0,0 +1,286 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type Mode int
+
+const (
+	ModeReplay Mode = iota
+	ModeRecord
+	ModeReplayOrRecord
+)
+
+func ParseMode(s string) (Mode, error) {
+	switch s {
+	case "", "replay":
+		return ModeReplay, nil
+	case "record":
+		return ModeRecord, nil
+	case "replay_or_record":
+		return ModeReplayOrRecord, nil
+	default:
+		return 0, fmt.Errorf("unknown cassette mode %q", s)
+	}
+}
+
+var ErrNoInteraction = errors.New("no recorded interaction matches request")
+
+type Config struct {
+	Path             string
+	Mode             Mode
+	Matcher          Matcher
+	ScrubHeaders     []string
+	ScrubQueryParams []string
+	ScrubBodyFields  []string
+	// AllowRepeats lets replay serve an already used interaction again once
+	// every matching one has been consumed.
+	AllowRepeats bool
+}
+
+type Recorder struct {
+	mu       sync.Mutex
+	cfg      Config
+	cassette *Cassette
+	used     []bool
+	next     http.RoundTripper
+}
+
+func (r *Recorder) Wrap(next http.RoundTripper) http.RoundTripper {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.next = next
+	return r
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := r.recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.cfg.Mode != ModeRecord {
+		if interaction, ok := r.find(recorded); ok {
+			return replay(req, interaction.Response), nil
+		}
+		if r.cfg.Mode == ModeReplay {
+			return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, recorded.Method, recorded.URL)
+		}
+	}
+
+	return r.record(req, recorded)
+}
+
+func (r *Recorder) find(req RecordedRequest) (Interaction, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, interaction := range r.cassette.Interactions {
+		if !r.cfg.Matcher(req, interaction.Request) {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return interaction, true
+		}
+		last = i
+	}
+
+	if r.cfg.AllowRepeats && last >= 0 {
+		return r.cassette.Interactions[last], true
+	}
+	return Interaction{}, false
+}
+
+func (r *Recorder) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	next := r.next
+	r.mu.Unlock()
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction := Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Headers:    r.scrubHeaders(resp.Header),
+			Body:       r.scrubBody(body),
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.used = append(r.used, true)
+	if err := r.cassette.Save(r.cfg.Path); err != nil {
+		return nil, fmt.Errorf("failed to save cassette: %w", err)
+	}
+	return resp, nil
+}
+
+func (r *Recorder) recordRequest(req *http.Request) (RecordedRequest, error) {
+	var body []byte
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return RecordedRequest{}, err
+		}
+		body, err = io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return RecordedRequest{}, err
+		}
+	}
+
+	return RecordedRequest{
+		Method:  req.Method,
+		URL:     r.scrubURL(req.URL),
+		Headers: r.scrubHeaders(req.Header),
+		Body:    r.scrubBody(body),
+	}, nil
+}
+
+func (r *Recorder) scrubHeaders(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+
+	scrubbed := h.Clone()
+	for _, name := range r.cfg.ScrubHeaders {
+		if _, ok := scrubbed[http.CanonicalHeaderKey(name)]; ok {
+			scrubbed.Set(name, Redacted)
+		}
+	}
+	return scrubbed
+}
+
+func (r *Recorder) scrubURL(u *url.URL) string {
+	if len(r.cfg.ScrubQueryParams) == 0 || u.RawQuery == "" {
+		return u.String()
+	}
+
+	clone := *u
+	q := clone.Query()
+	for _, name := range r.cfg.ScrubQueryParams {
+		for key := range q {
+			if strings.EqualFold(key, name) {
+				q.Set(key, Redacted)
+			}
+		}
+	}
+	clone.RawQuery = q.Encode()
+	return clone.String()
+}
+
+// scrubBody redacts the configured fields of a JSON body. Bodies that are not
+// JSON, or contain none of the fields, are kept byte for byte.
+func (r *Recorder) scrubBody(body []byte) string {
+	if len(r.cfg.ScrubBodyFields) == 0 || len(body) == 0 {
+		return string(body)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil || !r.scrubValue(v) {
+		return string(body)
+	}
+
+	scrubbed, err := json.Marshal(v)
+	if err != nil {
+		return string(body)
+	}
+	return string(scrubbed)
+}
+
+func (r *Recorder) scrubValue(v any) bool {
+	scrubbed := false
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if slices.ContainsFunc(r.cfg.ScrubBodyFields, func(name string) bool { return strings.EqualFold(key, name) }) {
+				v[key] = Redacted
+				scrubbed = true
+				continue
+			}
+			scrubbed = r.scrubValue(value) || scrubbed
+		}
+	case []any:
+		for _, value := range v {
+			scrubbed = r.scrubValue(value) || scrubbed
+		}
+	}
+	return scrubbed
+}
+
+func replay(req *http.Request, recorded RecordedResponse) *http.Response {
+	return &http.Response{
+		Status:        recorded.Status,
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Headers.Clone(),
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}
+}
+
+func NewRecorder(cfg Config) (*Recorder, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("cassette path is required")
+	}
+	if cfg.Matcher == nil {
+		cfg.Matcher = MatchMethodURL
+	}
+	if cfg.ScrubHeaders == nil {
+		cfg.ScrubHeaders = DefaultScrubHeaders
+	}
+	if cfg.ScrubBodyFields == nil {
+		cfg.ScrubBodyFields = DefaultScrubBodyFields
+	}
+
+	c := &Cassette{Version: formatVersion}
+	if cfg.Mode != ModeRecord {
+		loaded, err := Load(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		c = loaded
+	}
+
+	return &Recorder{
+		cfg:      cfg,
+		cassette: c,
+		used:     make([]bool, len(c.Interactions)),
+	}, nil
+}
@@ -0,0 +1,91 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordScrubsAndReplays(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"user": {"id": 7, "access_token": "tok-123"}, "items": [{"secret": "s"}]}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder, err := NewRecorder(Config{Path: path, Mode: ModeRecord, ScrubQueryParams: []string{"key"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: recorder.Wrap(http.DefaultTransport)}
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/login?key=k1", strings.NewReader(`{"email": "a@example.com", "Password": "hunter2"}`))
+	req.Header.Set("Authorization", "Bearer live-token")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "tok-123") {
+		t.Errorf("the live response should reach the caller unscrubbed, got %s", body)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Interactions) != 1 {
+		t.Fatalf("expected 1 interaction, got %d", len(c.Interactions))
+	}
+	got := c.Interactions[0]
+	for _, leaked := range []string{"live-token", "hunter2", "tok-123", `"s"`, "session=abc", "k1"} {
+		for _, field := range []string{got.Request.URL, got.Request.Body, got.Response.Body, strings.Join(got.Request.Headers["Authorization"], ""), strings.Join(got.Response.Headers["Set-Cookie"], "")} {
+			if strings.Contains(field, leaked) {
+				t.Errorf("cassette contains %s: %s", leaked, field)
+			}
+		}
+	}
+	if !strings.Contains(got.Request.Body, "a@example.com") || !strings.Contains(got.Response.Body, `"id":7`) {
+		t.Errorf("unrelated fields should be kept, got %s and %s", got.Request.Body, got.Response.Body)
+	}
+
+	replayer, err := NewRecorder(Config{Path: path, Mode: ModeReplay, ScrubQueryParams: []string{"key"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = &http.Client{Transport: replayer.Wrap(nil)}
+
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/login?key=other", strings.NewReader(`{}`))
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the recorded status, got %d", resp.StatusCode)
+	}
+
+	_, err = client.Get(server.URL + "/login")
+	if !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("expected ErrNoInteraction once the interaction is used, got %v", err)
+	}
+}
+
+func TestScrubBodyKeepsNonJSON(t *testing.T) {
+	r, err := NewRecorder(Config{Path: "unused.json", Mode: ModeRecord})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{"", "plain text token=abc", `{"name": "mug", "price": 14.00}`} {
+		if got := r.scrubBody([]byte(body)); got != body {
+			t.Errorf("scrubBody(%q) = %q, want it unchanged", body, got)
+		}
+	}
+}
@@ -0,0 +1,405 @@
+package checkout
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrUnknownMethod = errors.New("unknown shipping method")
+	ErrUnknownRegion = errors.New("unknown tax region")
+	ErrUnavailable   = errors.New("shipping method not available for this cart")
+)
+
+const (
+	ShippingFlat   = "flat"
+	ShippingWeight = "weight"
+	ShippingPrice  = "price"
+)
+
+// Tier charges Rate for carts up to UpTo kilograms or store currency units. A
+// tier without UpTo covers everything above the previous tiers.
+type Tier struct {
+	UpTo float64 `json:"up_to,omitempty"`
+	Rate float64 `json:"rate"`
+}
+
+type ShippingMethod struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Type is flat, weight or price.
+	Type  string  `json:"type"`
+	Rate  float64 `json:"rate,omitempty"`
+	Tiers []Tier  `json:"tiers,omitempty"`
+	// FreeOver waives shipping once the discounted subtotal reaches it.
+	FreeOver float64 `json:"free_over,omitempty"`
+}
+
+// Weights supplies product weights in kilograms for backends that do not
+// report them, by product, then by category, then as a default.
+type Weights struct {
+	Default    float64         `json:"default"`
+	Categories map[int]float64 `json:"categories,omitempty"`
+	Products   map[int]float64 `json:"products,omitempty"`
+}
+
+type Shipping struct {
+	Default string           `json:"default"`
+	Methods []ShippingMethod `json:"methods"`
+	Weights Weights          `json:"weights"`
+}
+
+// Region is a tax table. Rates are percentages; CategoryRates override Rate
+// for products in those categories.
+type Region struct {
+	Name            string          `json:"name"`
+	Rate            float64         `json:"rate"`
+	CategoryRates   map[int]float64 `json:"category_rates,omitempty"`
+	ShippingTaxable bool            `json:"shipping_taxable,omitempty"`
+}
+
+type Tax struct {
+	Default string            `json:"default"`
+	Regions map[string]Region `json:"regions"`
+}
+
+type Rules struct {
+	Shipping Shipping `json:"shipping"`
+	Tax      Tax      `json:"tax"`
+}
+
+type Request struct {
+	ShippingMethod string
+	Region         string
+}
+
+// ShippingEstimate leaves FreeOver zero when the method is never free.
+type ShippingEstimate struct {
+	Method   string      `json:"method"`
+	Name     string      `json:"name"`
+	Type     string      `json:"type"`
+	Amount   money.Money `json:"amount"`
+	FreeOver money.Money `json:"free_over"`
+	Free     bool        `json:"free,omitempty"`
+}
+
+type TaxEstimate struct {
+	Region string      `json:"region"`
+	Name   string      `json:"name"`
+	Amount money.Money `json:"amount"`
+}
+
+type Discount struct {
+	Code        string      `json:"code"`
+	Description string      `json:"description"`
+	Amount      money.Money `json:"amount"`
+}
+
+// Estimate holds every amount in the store currency.
+type Estimate struct {
+	Subtotal      money.Money        `json:"subtotal"`
+	Discounts     []Discount         `json:"discounts"`
+	DiscountTotal money.Money        `json:"discount_total"`
+	WeightKg      float64            `json:"weight_kg"`
+	Shipping      *ShippingEstimate  `json:"shipping,omitempty"`
+	Tax           *TaxEstimate       `json:"tax,omitempty"`
+	Total         money.Money        `json:"total"`
+	Alternatives  []ShippingEstimate `json:"alternatives,omitempty"`
+}
+
+// Merchandise is what the items cost after discounts.
+func (est Estimate) Merchandise() money.Money {
+	return est.Subtotal.Sub(est.DiscountTotal)
+}
+
+// Engine estimates shipping and tax for a cart from locally configured rules.
+// The backend does not expose either, so the figures are estimates.
+type Engine struct {
+	rules    Rules
+	currency string
+}
+
+func (e *Engine) HasShipping() bool {
+	return len(e.rules.Shipping.Methods) > 0
+}
+
+func (e *Engine) HasTax() bool {
+	return len(e.rules.Tax.Regions) > 0
+}
+
+func (e *Engine) Methods() []string {
+	ids := make([]string, 0, len(e.rules.Shipping.Methods))
+	for _, m := range e.rules.Shipping.Methods {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func (e *Engine) Regions() []string {
+	ids := make([]string, 0, len(e.rules.Tax.Regions))
+	for id := range e.rules.Tax.Regions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Estimate adds shipping and tax to the cart after the given discounts. An
+// empty method or region selects the configured default; shipping or tax is
+// left out when no rules are configured for it.
+func (e *Engine) Estimate(cart *models.Cart, discounts []models.Discount, req Request) (Estimate, error) {
+	est := Estimate{
+		Subtotal:      cart.TotalIn(e.currency),
+		Discounts:     make([]Discount, 0, len(discounts)),
+		DiscountTotal: money.Zero(e.currency),
+	}
+	for _, d := range discounts {
+		amount := d.AmountIn(e.currency)
+		est.Discounts = append(est.Discounts, Discount{Code: d.Code, Description: d.Description, Amount: amount})
+		est.DiscountTotal = est.DiscountTotal.Add(amount)
+	}
+	est.DiscountTotal = money.Min(est.DiscountTotal, est.Subtotal)
+	merchandise := est.Merchandise()
+
+	for _, item := range cart.CartItems {
+		est.WeightKg += e.weight(item.Product) * float64(item.Quantity)
+	}
+	est.WeightKg = math.Round(est.WeightKg*1000) / 1000
+
+	if e.HasShipping() {
+		method, err := e.method(req.ShippingMethod)
+		if err != nil {
+			return Estimate{}, err
+		}
+		shipping, err := e.shipping(method, merchandise, est.WeightKg)
+		if err != nil {
+			return Estimate{}, err
+		}
+		est.Shipping = &shipping
+
+		for _, m := range e.rules.Shipping.Methods {
+			if m.ID == method.ID {
+				continue
+			}
+			if alt, err := e.shipping(m, merchandise, est.WeightKg); err == nil {
+				est.Alternatives = append(est.Alternatives, alt)
+			}
+		}
+	}
+
+	if e.HasTax() {
+		id, region, err := e.region(req.Region)
+		if err != nil {
+			return Estimate{}, err
+		}
+		tax := TaxEstimate{Region: id, Name: region.Name, Amount: money.Zero(e.currency)}
+
+		// Discounts are spread over the lines in proportion to their subtotal
+		// so category rates apply to what is actually paid.
+		for _, item := range cart.CartItems {
+			paid := item.SubtotalIn(e.currency)
+			if est.Subtotal.Amount > 0 {
+				paid = paid.MulDiv(merchandise.Amount, est.Subtotal.Amount)
+			}
+			tax.Amount = tax.Amount.Add(paid.Percent(region.rate(item.Product.CategoryId)))
+		}
+		if region.ShippingTaxable && est.Shipping != nil {
+			tax.Amount = tax.Amount.Add(est.Shipping.Amount.Percent(region.Rate))
+		}
+		est.Tax = &tax
+	}
+
+	est.Total = merchandise
+	if est.Shipping != nil {
+		est.Total = est.Total.Add(est.Shipping.Amount)
+	}
+	if est.Tax != nil {
+		est.Total = est.Total.Add(est.Tax.Amount)
+	}
+	return est, nil
+}
+
+func (e *Engine) method(id string) (ShippingMethod, error) {
+	if id == "" {
+		id = e.rules.Shipping.Default
+	}
+	idx := slices.IndexFunc(e.rules.Shipping.Methods, func(m ShippingMethod) bool { return strings.EqualFold(m.ID, id) })
+	if idx < 0 {
+		return ShippingMethod{}, fmt.Errorf("%w %q; available: %s", ErrUnknownMethod, id, strings.Join(e.Methods(), ", "))
+	}
+	return e.rules.Shipping.Methods[idx], nil
+}
+
+func (e *Engine) region(id string) (string, Region, error) {
+	if id == "" {
+		id = e.rules.Tax.Default
+	}
+	for key, region := range e.rules.Tax.Regions {
+		if strings.EqualFold(key, id) {
+			return key, region, nil
+		}
+	}
+	return "", Region{}, fmt.Errorf("%w %q; available: %s", ErrUnknownRegion, id, strings.Join(e.Regions(), ", "))
+}
+
+func (e *Engine) shipping(m ShippingMethod, merchandise money.Money, weight float64) (ShippingEstimate, error) {
+	est := ShippingEstimate{
+		Method:   m.ID,
+		Name:     m.Name,
+		Type:     m.Type,
+		Amount:   money.Zero(e.currency),
+		FreeOver: e.amount(m.FreeOver),
+	}
+
+	if !est.FreeOver.IsZero() && merchandise.Cmp(est.FreeOver) >= 0 {
+		est.Free = true
+		return est, nil
+	}
+
+	switch m.Type {
+	case ShippingFlat:
+		est.Amount = e.amount(m.Rate)
+	case ShippingWeight:
+		tier, ok := findTier(m.Tiers, func(upTo float64) bool { return weight <= upTo })
+		if !ok {
+			return ShippingEstimate{}, fmt.Errorf("%w: %s ships at most %g kg, the cart weighs %g kg", ErrUnavailable, m.ID, lastLimit(m.Tiers), weight)
+		}
+		est.Amount = e.amount(tier.Rate)
+	case ShippingPrice:
+		tier, ok := findTier(m.Tiers, func(upTo float64) bool { return merchandise.Cmp(e.amount(upTo)) <= 0 })
+		if !ok {
+			return ShippingEstimate{}, fmt.Errorf("%w: %s covers orders up to %s", ErrUnavailable, m.ID, e.amount(lastLimit(m.Tiers)))
+		}
+		est.Amount = e.amount(tier.Rate)
+	}
+	return est, nil
+}
+
+// amount turns a configured rate or limit into money in the store currency.
+func (e *Engine) amount(v float64) money.Money {
+	return money.FromFloat(v, e.currency)
+}
+
+func (e *Engine) weight(p models.Product) float64 {
+	w := e.rules.Shipping.Weights
+	if p.Weight > 0 {
+		return p.Weight
+	}
+	if kg, ok := w.Products[p.Id]; ok {
+		return kg
+	}
+	if kg, ok := w.Categories[p.CategoryId]; ok {
+		return kg
+	}
+	return w.Default
+}
+
+func (r Region) rate(categoryID int) float64 {
+	if rate, ok := r.CategoryRates[categoryID]; ok {
+		return rate
+	}
+	return r.Rate
+}
+
+// findTier returns the first tier whose UpTo the cart is within.
+func findTier(tiers []Tier, within func(upTo float64) bool) (Tier, bool) {
+	for _, t := range tiers {
+		if t.UpTo == 0 || within(t.UpTo) {
+			return t, true
+		}
+	}
+	return Tier{}, false
+}
+
+func lastLimit(tiers []Tier) float64 {
+	if len(tiers) == 0 {
+		return 0
+	}
+	return tiers[len(tiers)-1].UpTo
+}
+
+func validate(rules Rules) error {
+	seen := make(map[string]bool)
+	for _, m := range rules.Shipping.Methods {
+		switch {
+		case m.ID == "":
+			return errors.New("shipping method without id")
+		case seen[strings.ToLower(m.ID)]:
+			return fmt.Errorf("duplicate shipping method %s", m.ID)
+		case m.Type == ShippingFlat && m.Rate < 0:
+			return fmt.Errorf("shipping method %s: rate must not be negative", m.ID)
+		case (m.Type == ShippingWeight || m.Type == ShippingPrice) && len(m.Tiers) == 0:
+			return fmt.Errorf("shipping method %s: %s shipping needs tiers", m.ID, m.Type)
+		case m.Type != ShippingFlat && m.Type != ShippingWeight && m.Type != ShippingPrice:
+			return fmt.Errorf("shipping method %s: unknown type %q", m.ID, m.Type)
+		}
+		seen[strings.ToLower(m.ID)] = true
+
+		for i, t := range m.Tiers {
+			if t.Rate < 0 {
+				return fmt.Errorf("shipping method %s: tier rates must not be negative", m.ID)
+			}
+			if t.UpTo == 0 && i != len(m.Tiers)-1 {
+				return fmt.Errorf("shipping method %s: only the last tier may omit up_to", m.ID)
+			}
+			if i > 0 && t.UpTo != 0 && t.UpTo <= m.Tiers[i-1].UpTo {
+				return fmt.Errorf("shipping method %s: tiers must be in ascending order", m.ID)
+			}
+		}
+	}
+	if len(rules.Shipping.Methods) > 0 && !seen[strings.ToLower(rules.Shipping.Default)] {
+		return fmt.Errorf("default shipping method %q is not configured", rules.Shipping.Default)
+	}
+
+	for id, region := range rules.Tax.Regions {
+		if region.Rate < 0 || region.Rate > 100 {
+			return fmt.Errorf("tax region %s: rate must be between 0 and 100", id)
+		}
+		for _, rate := range region.CategoryRates {
+			if rate < 0 || rate > 100 {
+				return fmt.Errorf("tax region %s: category rates must be between 0 and 100", id)
+			}
+		}
+	}
+	if len(rules.Tax.Regions) > 0 {
+		if _, ok := rules.Tax.Regions[rules.Tax.Default]; !ok {
+			return fmt.Errorf("default tax region %q is not configured", rules.Tax.Default)
+		}
+	}
+	return nil
+}
+
+// Load reads shipping and tax rules from a JSON file. Rates are in the store
+// currency. An empty path yields an engine without rules.
+func Load(path, currency string) (*Engine, error) {
+	if path == "" {
+		return NewEngine(Rules{}, currency)
+	}
+
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules Rules
+	if err := json.Unmarshal(bs, &rules); err != nil {
+		return nil, fmt.Errorf("invalid checkout rules file %s: %w", path, err)
+	}
+
+	return NewEngine(rules, currency)
+}
+
+func NewEngine(rules Rules, currency string) (*Engine, error) {
+	if err := validate(rules); err != nil {
+		return nil, err
+	}
+	return &Engine{rules: rules, currency: currency}, nil
+}
@@ -0,0 +1,234 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("store temporarily unavailable")
+
+type CircuitOpenError struct {
+	Group   string
+	RetryIn time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	if e.RetryIn > 0 {
+		return fmt.Sprintf("%s: circuit open for %s, retry in %s", ErrCircuitOpen, e.Group, e.RetryIn)
+	}
+	return fmt.Sprintf("%s: circuit half-open for %s, retry shortly", ErrCircuitOpen, e.Group)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+type BreakerConfig struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	HalfOpenMaxCalls int
+	OnStateChange    func(ctx context.Context, group string, from, to BreakerState)
+}
+
+func (c BreakerConfig) enabled() bool {
+	return c.FailureThreshold > 0
+}
+
+type BreakerStats struct {
+	State    BreakerState
+	Failures int
+	OpenedAt time.Time
+}
+
+type stateChange struct {
+	from, to BreakerState
+}
+
+type breaker struct {
+	mu       sync.Mutex
+	group    string
+	cfg      BreakerConfig
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probes   int
+	changes  []stateChange
+}
+
+func (b *breaker) allow(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.unlock(ctx)
+
+	switch b.state {
+	case BreakerOpen:
+		remaining := b.cfg.OpenTimeout - time.Since(b.openedAt)
+		if remaining > 0 {
+			return &CircuitOpenError{Group: b.group, RetryIn: remaining.Round(time.Second)}
+		}
+		b.transition(BreakerHalfOpen)
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probes >= max(b.cfg.HalfOpenMaxCalls, 1) {
+			return &CircuitOpenError{Group: b.group}
+		}
+		b.probes++
+	}
+
+	return nil
+}
+
+func (b *breaker) record(ctx context.Context, success bool) {
+	b.mu.Lock()
+	defer b.unlock(ctx)
+
+	if b.state == BreakerHalfOpen {
+		b.probes--
+		if success {
+			b.failures = 0
+			b.transition(BreakerClosed)
+		} else {
+			b.open()
+		}
+		return
+	}
+
+	if success {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerClosed && b.failures >= b.cfg.FailureThreshold {
+		b.open()
+	}
+}
+
+// release gives back a half-open probe slot for a call whose outcome says
+// nothing about backend health, such as a cancelled request.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+func (b *breaker) open() {
+	b.openedAt = time.Now()
+	b.transition(BreakerOpen)
+}
+
+func (b *breaker) transition(to BreakerState) {
+	from := b.state
+	if from == to {
+		return
+	}
+	b.state = to
+	if to != BreakerHalfOpen {
+		b.probes = 0
+	}
+	b.changes = append(b.changes, stateChange{from: from, to: to})
+}
+
+// unlock releases the breaker and only then reports the transitions made while
+// it was held, so that OnStateChange may read the breaker stats.
+func (b *breaker) unlock(ctx context.Context) {
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+
+	if b.cfg.OnStateChange == nil {
+		return
+	}
+	for _, c := range changes {
+		b.cfg.OnStateChange(ctx, b.group, c.from, c.to)
+	}
+}
+
+func (b *breaker) stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return BreakerStats{
+		State:    b.state,
+		Failures: b.failures,
+		OpenedAt: b.openedAt,
+	}
+}
+
+type breakerSet struct {
+	mu       sync.Mutex
+	cfg      BreakerConfig
+	breakers map[string]*breaker
+}
+
+func (s *breakerSet) get(group string) *breaker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.breakers[group]
+	if !ok {
+		b = &breaker{group: group, cfg: s.cfg}
+		s.breakers[group] = b
+	}
+	return b
+}
+
+func (s *breakerSet) stats() map[string]BreakerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make(map[string]BreakerStats, len(s.breakers))
+	for group, b := range s.breakers {
+		stats[group] = b.stats()
+	}
+	return stats
+}
+
+func newBreakerSet(cfg BreakerConfig) *breakerSet {
+	return &breakerSet{
+		cfg:      cfg,
+		breakers: make(map[string]*breaker),
+	}
+}
+
+// RouteGroup maps a request path to the backend area it belongs to, so that
+// an outage of one area does not trip the others.
+func RouteGroup(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	segment, _, _ = strings.Cut(segment, "?")
+
+	switch segment {
+	case "products", "search", "categories":
+		return "catalog"
+	case "":
+		return "root"
+	default:
+		return segment
+	}
+}
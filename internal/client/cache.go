@@ -0,0 +1,153 @@
+package client
+
+import (
+	"container/list"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type CacheConfig struct {
+	MaxEntries int
+	TTL        time.Duration
+}
+
+func (c CacheConfig) enabled() bool {
+	return c.MaxEntries > 0 && c.TTL > 0
+}
+
+type CacheStats struct {
+	Entries int
+	Hits    uint64
+	Misses  uint64
+}
+
+type cacheEntry struct {
+	key     string
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+func (e *cacheEntry) fresh() bool {
+	return time.Now().Before(e.expires)
+}
+
+type responseCache struct {
+	mu      sync.Mutex
+	cfg     CacheConfig
+	order   *list.List
+	entries map[string]*list.Element
+	hits    uint64
+	misses  uint64
+}
+
+// get returns a copy of the entry, since refresh may replace it while the
+// caller is revalidating.
+func (c *responseCache) get(key string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return cacheEntry{}, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if entry.fresh() {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	c.order.MoveToFront(el)
+	return *entry, true
+}
+
+func (c *responseCache) put(entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[entry.key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[entry.key] = c.order.PushFront(entry)
+	for c.order.Len() > c.cfg.MaxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *responseCache) refresh(key string, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		entry := *el.Value.(*cacheEntry)
+		entry.expires = expires
+		el.Value = &entry
+	}
+}
+
+func (c *responseCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.order.Remove(el)
+		delete(c.entries, key)
+	}
+}
+
+func (c *responseCache) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{
+		Entries: c.order.Len(),
+		Hits:    c.hits,
+		Misses:  c.misses,
+	}
+}
+
+// lifetime works out how long a response may be served from the cache,
+// honouring Cache-Control. The second result is false when the response must
+// not be stored at all.
+func (c *responseCache) lifetime(header http.Header) (time.Duration, bool) {
+	ttl := c.cfg.TTL
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(strings.ToLower(directive)), "=")
+		switch name {
+		case "no-store", "private":
+			return 0, false
+		case "no-cache":
+			ttl = 0
+		case "max-age":
+			if seconds, err := strconv.Atoi(value); err == nil {
+				ttl = min(ttl, time.Duration(seconds)*time.Second)
+			}
+		}
+	}
+
+	// A zero lifetime is only useful if the entry can be revalidated.
+	if ttl <= 0 && header.Get("ETag") == "" {
+		return 0, false
+	}
+	return ttl, true
+}
+
+func newResponseCache(cfg CacheConfig) *responseCache {
+	if !cfg.enabled() {
+		return nil
+	}
+	return &responseCache{
+		cfg:     cfg,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"sync"
+)
+
+type driftReporter struct {
+	seen sync.Map
+}
+
+func DecodeEnvelope[T any](ctx context.Context, c *RestClient, data []byte) (*models.Envelope[T], error) {
+	var envelope models.Envelope[T]
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+
+	if c.strictDecoding {
+		c.reportDrift(ctx, data, &envelope, fmt.Sprintf("Envelope[%T]", envelope.Data))
+	}
+
+	return &envelope, nil
+}
+
+func (c *RestClient) reportDrift(ctx context.Context, data []byte, v any, typeName string) {
+	fields, err := models.UnknownFields(data, v)
+	if err != nil || len(fields) == 0 {
+		return
+	}
+
+	fresh := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if _, loaded := c.drift.seen.LoadOrStore(typeName+"#"+field, struct{}{}); !loaded {
+			fresh = append(fresh, field)
+		}
+	}
+
+	if len(fresh) > 0 {
+		c.logger.WarnContext(ctx, "Backend response contains fields unknown to the client models", "type", typeName, "fields", fresh)
+	}
+}
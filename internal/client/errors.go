@@ -0,0 +1,109 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const maxErrorBodySize = 64 << 10
+
+var requestIDHeaders = []string{"X-Request-ID", "X-Correlation-ID", "X-Amzn-RequestId"}
+
+type APIError struct {
+	StatusCode int
+	Status     string
+	Method     string
+	Route      string
+	Message    string
+	Detail     string
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("API error from %s %s: %s", e.Method, e.Route, e.Status)
+	if reason := e.Reason(); reason != "" {
+		msg += ": " + reason
+	}
+	if e.RequestID != "" {
+		msg += " (request id: " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// Reason returns the most specific explanation the backend gave.
+func (e *APIError) Reason() string {
+	switch {
+	case e.Detail != "" && e.Message != "" && e.Detail != e.Message:
+		return e.Message + " - " + e.Detail
+	case e.Detail != "":
+		return e.Detail
+	default:
+		return e.Message
+	}
+}
+
+type errorEnvelope struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Error   json.RawMessage `json:"error"`
+}
+
+func newAPIError(req *http.Request, resp *http.Response, route string) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Method:     req.Method,
+		Route:      route,
+		RequestID:  responseRequestID(resp),
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil || len(body) == 0 {
+		return apiErr
+	}
+
+	var envelope errorEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		apiErr.Detail = strings.TrimSpace(string(body))
+		return apiErr
+	}
+
+	apiErr.Message = envelope.Message
+	apiErr.Detail = errorDetail(envelope.Error)
+	return apiErr
+}
+
+// errorDetail accepts the "error" field as a plain string or as an object,
+// since not every backend handler formats it the same way.
+func errorDetail(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+
+	var obj struct {
+		Message string `json:"message"`
+		Code    string `json:"code"`
+	}
+	if err := json.Unmarshal(raw, &obj); err == nil && obj.Message != "" {
+		return obj.Message
+	}
+
+	return string(raw)
+}
+
+func responseRequestID(resp *http.Response) string {
+	for _, header := range requestIDHeaders {
+		if id := resp.Header.Get(header); id != "" {
+			return id
+		}
+	}
+	return ""
+}
@@ -2,101 +2,312 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
 	"encoding/json"
-	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/correlation"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
 )
 
+type Options struct {
+	ConnectTimeout time.Duration
+	HeaderTimeout  time.Duration
+	Timeout        time.Duration
+	Retry          RetryPolicy
+	Breaker        BreakerConfig
+	StrictDecoding bool
+	Cache          CacheConfig
+	RateLimits     RateLimitConfig
+	TLS            TLSConfig
+	Proxy          ProxyConfig
+	WrapTransport  func(http.RoundTripper) http.RoundTripper
+	// StatsInterval is how often LogStats runs in the background. Zero
+	// disables it.
+	StatsInterval time.Duration
+}
+
 type RestClient struct {
-	client       *http.Client
-	baseURL      string
-	defaultToken string
-	useToken     bool
-	logger       *slog.Logger
+	client         *http.Client
+	baseURL        string
+	defaultToken   string
+	useToken       bool
+	idempotencyKey string
+	retry          RetryPolicy
+	breakers       *breakerSet
+	strictDecoding bool
+	drift          *driftReporter
+	cache          *responseCache
+	limiter        *rateLimiter
+	stop           func()
+	logger         *slog.Logger
+}
+
+type Request struct {
+	Method  string
+	Path    string
+	Query   map[string]string
+	Headers map[string]string
+	Body    any
 }
 
-func (c *RestClient) prepareRequest(method, path string, queryParams map[string]string, body any) (*http.Request, error) {
-	fullURL, err := url.Parse(c.baseURL + path)
+func (c *RestClient) prepareRequest(ctx context.Context, r Request) (*http.Request, error) {
+	fullURL, err := url.Parse(c.baseURL + r.Path)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(queryParams) > 0 {
+	if len(r.Query) > 0 {
 		q := fullURL.Query()
-		for k, v := range queryParams {
+		for k, v := range r.Query {
 			q.Set(k, v)
 		}
 		fullURL.RawQuery = q.Encode()
 	}
 
-	var bodyReader *bytes.Reader
-	if body != nil {
-		bodyBytes, err := json.Marshal(body)
+	var body io.Reader = http.NoBody
+	if r.Body != nil {
+		bodyBytes, err := json.Marshal(r.Body)
 		if err != nil {
 			return nil, err
 		}
-		bodyReader = bytes.NewReader(bodyBytes)
-	} else {
-		bodyReader = bytes.NewReader(nil)
+		body = bytes.NewReader(bodyBytes)
 	}
 
-	req, err := http.NewRequest(method, fullURL.String(), bodyReader)
+	req, err := http.NewRequestWithContext(ctx, r.Method, fullURL.String(), body)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if r.Body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	if c.useToken && c.defaultToken != "" {
 		req.Header.Set("Authorization", "Bearer "+c.defaultToken)
 	}
 
+	if c.idempotencyKey != "" {
+		req.Header.Set(IdempotencyKeyHeader, c.idempotencyKey)
+	}
+
+	if id := correlation.FromContext(ctx); id != "" {
+		req.Header.Set(correlation.Header, id)
+	}
+
+	for k, v := range r.Headers {
+		req.Header.Set(k, v)
+	}
+
 	return req, nil
 }
 
-func (c *RestClient) Get(path string, params map[string]string) ([]byte, error) {
-	req, err := c.prepareRequest("GET", path, params, nil)
+func (c *RestClient) attempt(req *http.Request) (*http.Response, error) {
+	group := RouteGroup(req.URL.Path)
+
+	waited, err := c.limiter.wait(req.Context(), group)
 	if err != nil {
+		c.logger.WarnContext(req.Context(), "REST API call rejected by rate limiter", "group", group, "method", req.Method, "url", req.URL.String(), "error", err)
 		return nil, err
 	}
+	if waited > 0 {
+		c.logger.DebugContext(req.Context(), "REST API call delayed by rate limiter", "group", group, "method", req.Method, "url", req.URL.String(), "wait", waited)
+	}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, err
+	if !c.breakers.cfg.enabled() {
+		return c.client.Do(req)
 	}
-	defer resp.Body.Close()
 
-	c.logger.Debug("REST API call", "method", req.Method, "url", req.URL.String(), "status", resp.StatusCode)
+	b := c.breakers.get(group)
+	if err := b.allow(req.Context()); err != nil {
+		return nil, err
+	}
 
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("API error from Get(): %s", resp.Status)
+	resp, err := c.client.Do(req)
+	switch {
+	case err != nil && req.Context().Err() != nil:
+		b.release()
+	case err != nil || resp.StatusCode >= 500:
+		b.record(req.Context(), false)
+	default:
+		b.record(req.Context(), true)
 	}
+	return resp, err
+}
+
+func (c *RestClient) send(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	retryable := c.retry.enabled() && isIdempotent(req)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.attempt(req)
+		if !retryable || attempt >= c.retry.MaxAttempts || !isRetryable(resp, err) {
+			return resp, err
+		}
 
-	return io.ReadAll(resp.Body)
+		wait := c.retry.delay(resp, attempt)
+		if !fitsDeadline(ctx, wait) {
+			c.logger.DebugContext(ctx, "Not retrying, backoff exceeds deadline", "method", req.Method, "url", req.URL.String(), "attempt", attempt, "wait", wait)
+			return resp, err
+		}
+
+		if resp != nil {
+			c.logger.WarnContext(ctx, "Retrying REST API call", "method", req.Method, "url", req.URL.String(), "attempt", attempt, "status", resp.StatusCode, "wait", wait)
+			discard(resp)
+		} else {
+			c.logger.WarnContext(ctx, "Retrying REST API call", "method", req.Method, "url", req.URL.String(), "attempt", attempt, "error", err, "wait", wait)
+		}
+
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
 }
 
-func (c *RestClient) Post(path string, body any) ([]byte, error) {
-	req, err := c.prepareRequest("POST", path, nil, body)
+func (c *RestClient) Do(ctx context.Context, r Request) ([]byte, error) {
+	if r.Method == "" {
+		r.Method = http.MethodGet
+	}
+
+	req, err := c.prepareRequest(ctx, r)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.client.Do(req)
+	cacheKey := ""
+	var cached *cacheEntry
+	if c.cacheable(req) {
+		cacheKey = req.Method + " " + req.URL.String()
+		if entry, ok := c.cache.get(cacheKey); ok {
+			if entry.fresh() {
+				c.logger.DebugContext(ctx, "REST API cache hit", "method", req.Method, "url", req.URL.String())
+				return entry.body, nil
+			}
+			if entry.etag != "" {
+				req.Header.Set("If-None-Match", entry.etag)
+				cached = &entry
+			}
+		}
+	}
+
+	resp, err := c.send(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	c.logger.Debug("REST API call", "method", req.Method, "url", req.URL.String(), "status", resp.StatusCode)
+	attrs := []any{"method", req.Method, "url", req.URL.String(), "status", resp.StatusCode}
+	if id := responseRequestID(resp); id != "" && id != req.Header.Get(correlation.Header) {
+		attrs = append(attrs, "backend_request_id", id)
+	}
+	c.logger.DebugContext(ctx, "REST API call", attrs...)
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		if ttl, ok := c.cache.lifetime(resp.Header); ok {
+			c.cache.refresh(cacheKey, time.Now().Add(ttl))
+		} else {
+			c.cache.remove(cacheKey)
+		}
+		return cached.body, nil
+	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("API error Post(): %s", resp.Status)
+		return nil, newAPIError(req, resp, r.Path)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheKey != "" {
+		if ttl, ok := c.cache.lifetime(resp.Header); ok && resp.StatusCode == http.StatusOK {
+			c.cache.put(&cacheEntry{
+				key:     cacheKey,
+				body:    body,
+				etag:    resp.Header.Get("ETag"),
+				expires: time.Now().Add(ttl),
+			})
+		} else {
+			c.cache.remove(cacheKey)
+		}
+	}
+
+	return body, nil
+}
+
+// cacheable limits caching to anonymous catalog reads, so that responses
+// belonging to one user's session are never served to another.
+func (c *RestClient) cacheable(req *http.Request) bool {
+	if c.cache == nil || req.Method != http.MethodGet {
+		return false
+	}
+	if req.Header.Get("Authorization") != "" {
+		return false
 	}
+	switch RouteGroup(req.URL.Path) {
+	case "cart", "orders":
+		return false
+	default:
+		return true
+	}
+}
+
+func (c *RestClient) Get(ctx context.Context, path string, params map[string]string) ([]byte, error) {
+	return c.Do(ctx, Request{Method: http.MethodGet, Path: path, Query: params})
+}
+
+func (c *RestClient) Post(ctx context.Context, path string, body any) ([]byte, error) {
+	return c.Do(ctx, Request{Method: http.MethodPost, Path: path, Body: body})
+}
+
+func (c *RestClient) Put(ctx context.Context, path string, body any) ([]byte, error) {
+	return c.Do(ctx, Request{Method: http.MethodPut, Path: path, Body: body})
+}
 
-	return io.ReadAll(resp.Body)
+func (c *RestClient) Patch(ctx context.Context, path string, body any) ([]byte, error) {
+	return c.Do(ctx, Request{Method: http.MethodPatch, Path: path, Body: body})
+}
+
+func (c *RestClient) Delete(ctx context.Context, path string) ([]byte, error) {
+	return c.Do(ctx, Request{Method: http.MethodDelete, Path: path})
+}
+
+func (c *RestClient) BreakerStats() map[string]BreakerStats {
+	return c.breakers.stats()
+}
+
+func (c *RestClient) LimiterStats() map[string]LimiterStats {
+	return c.limiter.stats()
+}
+
+func (c *RestClient) CacheStats() CacheStats {
+	if c.cache == nil {
+		return CacheStats{}
+	}
+	return c.cache.stats()
+}
+
+// Close stops background work such as TLS file reloading and stats logging.
+func (c *RestClient) Close() {
+	if c.stop != nil {
+		c.stop()
+	}
 }
 
 func (c *RestClient) WithToken() *RestClient {
@@ -105,12 +316,100 @@ func (c *RestClient) WithToken() *RestClient {
 	return &clone
 }
 
-func NewRestClient(baseURL, defaultToken string, logger *slog.Logger) *RestClient {
-	client := &http.Client{}
-	return &RestClient{
-		client:       client,
-		baseURL:      baseURL,
-		defaultToken: defaultToken,
-		logger:       logger,
+func (c *RestClient) WithIdempotencyKey(key string) *RestClient {
+	clone := *c
+	clone.idempotencyKey = key
+	return &clone
+}
+
+func NewIdempotencyKey() string {
+	return rand.Text()
+}
+
+func newTransport(opts Options) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.ConnectTimeout > 0 {
+		transport.DialContext = (&net.Dialer{
+			Timeout:   opts.ConnectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+		transport.TLSHandshakeTimeout = opts.ConnectTimeout
 	}
+	transport.ResponseHeaderTimeout = opts.HeaderTimeout
+
+	tlsConfig, err := buildTLSConfig(opts.TLS)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	proxy, err := proxyFunc(opts.Proxy)
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = proxy
+
+	return transport, nil
+}
+
+func NewRestClient(baseURL, defaultToken string, opts Options, logger *slog.Logger) (*RestClient, error) {
+	onStateChange := opts.Breaker.OnStateChange
+	opts.Breaker.OnStateChange = func(ctx context.Context, group string, from, to BreakerState) {
+		logger.WarnContext(ctx, "Circuit breaker state changed", "group", group, "from", from.String(), "to", to.String())
+		if onStateChange != nil {
+			onStateChange(ctx, group, from, to)
+		}
+	}
+
+	var transport http.RoundTripper
+	var stop func()
+	if opts.TLS.ReloadInterval > 0 && len(opts.TLS.files()) > 0 {
+		reloading, err := newReloadingTransport(opts, logger)
+		if err != nil {
+			return nil, err
+		}
+		transport, stop = reloading, reloading.Close
+	} else {
+		static, err := newTransport(opts)
+		if err != nil {
+			return nil, err
+		}
+		transport = static
+	}
+
+	if opts.WrapTransport != nil {
+		transport = opts.WrapTransport(transport)
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+	}
+	restClient := &RestClient{
+		client:         client,
+		baseURL:        baseURL,
+		defaultToken:   defaultToken,
+		retry:          opts.Retry,
+		breakers:       newBreakerSet(opts.Breaker),
+		strictDecoding: opts.StrictDecoding,
+		drift:          &driftReporter{},
+		cache:          newResponseCache(opts.Cache),
+		limiter:        newRateLimiter(opts.RateLimits),
+		stop:           stop,
+		logger:         logger,
+	}
+
+	if opts.StatsInterval > 0 {
+		done := make(chan struct{})
+		var once sync.Once
+		restClient.stop = func() {
+			once.Do(func() { close(done) })
+			if stop != nil {
+				stop()
+			}
+		}
+		go restClient.reportStats(opts.StatsInterval, done)
+	}
+
+	return restClient, nil
 }
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrRateLimited = errors.New("client-side rate limit reached")
+
+type RateLimitError struct {
+	Group   string
+	RetryIn time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s for %s, next slot in %s", ErrRateLimited, e.Group, e.RetryIn)
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+type RateLimit struct {
+	PerSecond float64
+	Burst     int
+}
+
+func (l RateLimit) enabled() bool {
+	return l.PerSecond > 0
+}
+
+type RateLimitConfig struct {
+	Default RateLimit
+	Groups  map[string]RateLimit
+}
+
+func (c RateLimitConfig) forGroup(group string) RateLimit {
+	if limit, ok := c.Groups[group]; ok {
+		return limit
+	}
+	return c.Default
+}
+
+type LimiterStats struct {
+	PerSecond  float64
+	Burst      int
+	Tokens     float64
+	Waits      uint64
+	Rejections uint64
+}
+
+type tokenBucket struct {
+	mu         sync.Mutex
+	limit      RateLimit
+	tokens     float64
+	last       time.Time
+	waits      uint64
+	rejections uint64
+}
+
+func (b *tokenBucket) burst() float64 {
+	return float64(max(b.limit.Burst, 1))
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = min(b.burst(), b.tokens+elapsed*b.limit.PerSecond)
+	b.last = now
+}
+
+// reserve takes a token, going into debt if none is available, and returns
+// how long the caller has to wait before the token is really theirs.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	b.waits++
+	return time.Duration(-b.tokens / b.limit.PerSecond * float64(time.Second))
+}
+
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.burst(), b.tokens+1)
+	b.waits--
+	b.rejections++
+}
+
+func (b *tokenBucket) stats() LimiterStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	return LimiterStats{
+		PerSecond:  b.limit.PerSecond,
+		Burst:      b.limit.Burst,
+		Tokens:     b.tokens,
+		Waits:      b.waits,
+		Rejections: b.rejections,
+	}
+}
+
+type rateLimiter struct {
+	mu      sync.Mutex
+	cfg     RateLimitConfig
+	buckets map[string]*tokenBucket
+}
+
+func (l *rateLimiter) bucket(group string) *tokenBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[group]
+	if !ok {
+		limit := l.cfg.forGroup(group)
+		if !limit.enabled() {
+			return nil
+		}
+		b = &tokenBucket{limit: limit, last: time.Now()}
+		b.tokens = b.burst()
+		l.buckets[group] = b
+	}
+	return b
+}
+
+// wait blocks until the group has capacity, or fails straight away when the
+// request deadline would expire before a slot frees up.
+func (l *rateLimiter) wait(ctx context.Context, group string) (time.Duration, error) {
+	b := l.bucket(group)
+	if b == nil {
+		return 0, nil
+	}
+
+	delay := b.reserve()
+	if delay == 0 {
+		return 0, nil
+	}
+
+	if !fitsDeadline(ctx, delay) {
+		b.cancel()
+		return 0, &RateLimitError{Group: group, RetryIn: delay.Round(100 * time.Millisecond)}
+	}
+
+	if err := sleep(ctx, delay); err != nil {
+		b.cancel()
+		return 0, err
+	}
+	return delay, nil
+}
+
+func (l *rateLimiter) stats() map[string]LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := make(map[string]LimiterStats, len(l.buckets))
+	for group, b := range l.buckets {
+		stats[group] = b.stats()
+	}
+	return stats
+}
+
+func newRateLimiter(cfg RateLimitConfig) *rateLimiter {
+	return &rateLimiter{
+		cfg:     cfg,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+)
+
+// LogStats writes the breaker and rate limiter state of every route group seen
+// so far and the cache counters as a single log record, which is what
+// log-based metrics collectors scrape.
+func (c *RestClient) LogStats(ctx context.Context) {
+	breakers := c.BreakerStats()
+	breakerAttrs := make([]any, 0, len(breakers))
+	for _, group := range slices.Sorted(maps.Keys(breakers)) {
+		s := breakers[group]
+		breakerAttrs = append(breakerAttrs, slog.Group(group, "state", s.State.String(), "failures", s.Failures))
+	}
+
+	limiters := c.LimiterStats()
+	limiterAttrs := make([]any, 0, len(limiters))
+	for _, group := range slices.Sorted(maps.Keys(limiters)) {
+		s := limiters[group]
+		limiterAttrs = append(limiterAttrs, slog.Group(group, "tokens", s.Tokens, "waits", s.Waits, "rejections", s.Rejections))
+	}
+
+	cache := c.CacheStats()
+
+	c.logger.InfoContext(ctx, "REST client stats",
+		slog.Group("breakers", breakerAttrs...),
+		slog.Group("limiters", limiterAttrs...),
+		slog.Group("cache", "entries", cache.Entries, "hits", cache.Hits, "misses", cache.Misses))
+}
+
+func (c *RestClient) reportStats(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			c.LogStats(context.Background())
+		}
+	}
+}
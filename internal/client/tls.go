@@ -0,0 +1,247 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type TLSConfig struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	MinVersion         string
+	InsecureSkipVerify bool
+	// ReloadInterval is how often CAFile, CertFile and KeyFile are checked
+	// for changes. Zero disables reloading.
+	ReloadInterval time.Duration
+}
+
+func (c TLSConfig) files() []string {
+	files := make([]string, 0, 3)
+	for _, f := range []string{c.CAFile, c.CertFile, c.KeyFile} {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+type ProxyConfig struct {
+	URL string
+	// NoProxy lists hosts, domain suffixes (".example.com"), IPs or CIDR
+	// ranges that bypass the proxy. "*" bypasses it for every host.
+	NoProxy []string
+}
+
+func ParseTLSVersion(s string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(s), "tls") {
+	case "":
+		return 0, nil
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unknown TLS version %q", s)
+	}
+}
+
+func buildTLSConfig(c TLSConfig) (*tls.Config, error) {
+	minVersion, err := ParseTLSVersion(c.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+
+	cfg := &tls.Config{
+		MinVersion:         minVersion,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, errors.New("client certificate and key must be configured together")
+		}
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+func proxyFunc(c ProxyConfig) (func(*http.Request) (*url.URL, error), error) {
+	if c.URL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	proxyURL, err := url.Parse(c.URL)
+	if err != nil || proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", c.URL)
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), c.NoProxy) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}, nil
+}
+
+func bypassProxy(host string, noProxy []string) bool {
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+
+	for _, entry := range noProxy {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case entry == "*":
+			return true
+		case ip != nil:
+			if _, network, err := net.ParseCIDR(entry); err == nil && network.Contains(ip) {
+				return true
+			}
+			if entryIP := net.ParseIP(entry); entryIP != nil && entryIP.Equal(ip) {
+				return true
+			}
+		default:
+			domain := strings.TrimPrefix(entry, ".")
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reloadingTransport delegates to an *http.Transport that is rebuilt whenever
+// one of the configured TLS files changes on disk. A failed rebuild keeps the
+// previous transport in place.
+type reloadingTransport struct {
+	current atomic.Pointer[http.Transport]
+	build   func() (*http.Transport, error)
+	files   []string
+	modTime map[string]time.Time
+	stop    chan struct{}
+	once    sync.Once
+	logger  *slog.Logger
+}
+
+func (t *reloadingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.current.Load().RoundTrip(req)
+}
+
+func (t *reloadingTransport) CloseIdleConnections() {
+	t.current.Load().CloseIdleConnections()
+}
+
+func (t *reloadingTransport) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			if modTime, changed := t.poll(); changed {
+				t.reload(modTime)
+			}
+		}
+	}
+}
+
+func (t *reloadingTransport) poll() (map[string]time.Time, bool) {
+	modTime := make(map[string]time.Time, len(t.files))
+	changed := false
+	for _, f := range t.files {
+		info, err := os.Stat(f)
+		if err != nil {
+			modTime[f] = t.modTime[f]
+			continue
+		}
+		modTime[f] = info.ModTime()
+		if !info.ModTime().Equal(t.modTime[f]) {
+			changed = true
+		}
+	}
+	return modTime, changed
+}
+
+// reload only records the new modification times once the rebuild succeeds,
+// so a certificate and key caught mid-rotation are retried on the next tick.
+func (t *reloadingTransport) reload(modTime map[string]time.Time) {
+	next, err := t.build()
+	if err != nil {
+		t.logger.WarnContext(context.Background(), "Failed to reload TLS settings, keeping previous configuration", "error", err)
+		return
+	}
+
+	t.modTime = modTime
+	previous := t.current.Swap(next)
+	previous.CloseIdleConnections()
+	t.logger.InfoContext(context.Background(), "Reloaded TLS settings", "files", t.files)
+}
+
+func (t *reloadingTransport) Close() {
+	t.once.Do(func() { close(t.stop) })
+}
+
+func newReloadingTransport(opts Options, logger *slog.Logger) (*reloadingTransport, error) {
+	build := func() (*http.Transport, error) { return newTransport(opts) }
+
+	initial, err := build()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &reloadingTransport{
+		build:   build,
+		files:   opts.TLS.files(),
+		modTime: make(map[string]time.Time),
+		stop:    make(chan struct{}),
+		logger:  logger,
+	}
+	t.current.Store(initial)
+	t.modTime, _ = t.poll()
+
+	go t.watch(opts.TLS.ReloadInterval)
+	return t, nil
+}
@@ -0,0 +1,104 @@
+package correlation
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+)
+
+const (
+	Header  = "X-Request-ID"
+	LogAttr = "correlation_id"
+)
+
+type contextKey struct{}
+
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+func NewID() string {
+	return rand.Text()[:12]
+}
+
+// Derive builds the correlation id for a JSON-RPC request within a session.
+// Requests without an id, such as notifications, get a fresh random one.
+func Derive(sessionID string, requestID any) string {
+	if requestID == nil {
+		return sessionID + "-" + NewID()
+	}
+	return fmt.Sprintf("%s-%v", sessionID, requestID)
+}
+
+// Handler adds the correlation id carried by the record's context to every
+// log record. Only the *Context logging methods pass that context through.
+type Handler struct {
+	next slog.Handler
+	// root is next before its first group was opened, and scope replays the
+	// groups and attributes added since, so the correlation id can be added
+	// at the top level rather than inside the innermost group.
+	root  slog.Handler
+	scope []func(slog.Handler) slog.Handler
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	id := FromContext(ctx)
+	if id == "" {
+		return h.next.Handle(ctx, record)
+	}
+	if len(h.scope) == 0 {
+		record = record.Clone()
+		record.AddAttrs(slog.String(LogAttr, id))
+		return h.next.Handle(ctx, record)
+	}
+
+	next := h.root.WithAttrs([]slog.Attr{slog.String(LogAttr, id)})
+	for _, apply := range h.scope {
+		next = apply(next)
+	}
+	return next.Handle(ctx, record)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(h.scope) == 0 {
+		return &Handler{next: h.next.WithAttrs(attrs)}
+	}
+	return h.within(h.next.WithAttrs(attrs), func(next slog.Handler) slog.Handler {
+		return next.WithAttrs(attrs)
+	})
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.within(h.next.WithGroup(name), func(next slog.Handler) slog.Handler {
+		return next.WithGroup(name)
+	})
+}
+
+func (h *Handler) within(next slog.Handler, apply func(slog.Handler) slog.Handler) *Handler {
+	root := h.root
+	if root == nil {
+		root = h.next
+	}
+	return &Handler{
+		next:  next,
+		root:  root,
+		scope: append(h.scope[:len(h.scope):len(h.scope)], apply),
+	}
+}
+
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
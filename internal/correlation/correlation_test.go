@@ -0,0 +1,31 @@
+package correlation
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestHandlerKeepsIDAtTopLevelInGroups(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil)))
+	ctx := WithID(t.Context(), "abc-1")
+
+	logger.With("service", "cart").WithGroup("request").With("tool", "view_cart").InfoContext(ctx, "called", "ms", 3)
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got[LogAttr] != "abc-1" || got["service"] != "cart" {
+		t.Errorf("expected correlation_id and service at the top level, got %v", got)
+	}
+	group, _ := got["request"].(map[string]any)
+	if group["tool"] != "view_cart" || group["ms"] != float64(3) {
+		t.Errorf("expected tool and ms in the request group, got %v", got)
+	}
+	if _, ok := group[LogAttr]; ok {
+		t.Errorf("correlation_id should not be inside the group, got %v", got)
+	}
+}
@@ -6,6 +6,8 @@ const (
 	ErrorMethodNotFound = -32601 // Method does not exist
 	ErrorInvalidParams  = -32602 // Invalid method parameters
 	ErrorInternal       = -32603 // Internal JSON-RPC error
+
+	ErrorServerNotInitialized = -32002 // Request received before initialization
 )
 
 func NewError(code int, message string, data any) *Error {
@@ -35,3 +37,7 @@ func NewInvalidParamsError(data any) *Error {
 func NewInternalError(data any) *Error {
 	return NewError(ErrorInternal, "Internal Error", data)
 }
+
+func NewServerNotInitializedError(data any) *Error {
+	return NewError(ErrorServerNotInitialized, "Server not initialized", data)
+}
@@ -2,18 +2,29 @@ package jsonrpc
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"sync"
+	"time"
 )
 
-type Handler func(params json.RawMessage) (any, error)
+type Handler func(ctx context.Context, params json.RawMessage) (any, error)
+
+var ErrRequestCancelled = errors.New("request cancelled by client")
 
 type Server struct {
 	handlers map[string]Handler
+	inline   map[string]bool
 	logger   *slog.Logger
+
+	mu       sync.Mutex
+	inFlight map[string]context.CancelCauseFunc
+	writeMu  sync.Mutex
 }
 
 func (s *Server) RegisterMethod(method string, handler Handler) {
@@ -21,7 +32,57 @@ func (s *Server) RegisterMethod(method string, handler Handler) {
 	s.logger.Debug("registered handler", "method", method)
 }
 
-func (s *Server) HandleRequest(req *Request) *Response {
+// RegisterInlineMethod registers a handler that runs on the read loop, so no
+// later message is dispatched until it has completed.
+func (s *Server) RegisterInlineMethod(method string, handler Handler) {
+	s.RegisterMethod(method, handler)
+	s.inline[method] = true
+}
+
+func (s *Server) Cancel(id any) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cancel, ok := s.inFlight[requestKey(id)]
+	if ok {
+		cancel(ErrRequestCancelled)
+	}
+	return ok
+}
+
+func (s *Server) track(ctx context.Context, req *Request) (context.Context, func()) {
+	if req.IsNotification() {
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	key := requestKey(req.Id)
+
+	s.mu.Lock()
+	s.inFlight[key] = cancel
+	s.mu.Unlock()
+
+	return ctx, func() {
+		s.mu.Lock()
+		delete(s.inFlight, key)
+		s.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the id of the JSON-RPC request being handled.
+func RequestIDFromContext(ctx context.Context) (any, bool) {
+	id := ctx.Value(requestIDKey{})
+	return id, id != nil
+}
+
+func requestKey(id any) string {
+	return fmt.Sprintf("%T:%v", id, id)
+}
+
+func (s *Server) HandleRequest(ctx context.Context, req *Request) *Response {
 	s.logger.Debug("Handling request", "method", req.Method, "id", req.Id)
 
 	if err := req.Validate(); err != nil {
@@ -37,7 +98,18 @@ func (s *Server) HandleRequest(req *Request) *Response {
 		return NewErrorResponse(req.Id, NewMethodNotFoundError(req.Method))
 	}
 
-	result, err := handler(req.Params)
+	ctx, done := s.track(ctx, req)
+	defer done()
+
+	if !req.IsNotification() {
+		ctx = context.WithValue(ctx, requestIDKey{}, req.Id)
+	}
+
+	result, err := handler(ctx, req.Params)
+	if errors.Is(context.Cause(ctx), ErrRequestCancelled) {
+		s.logger.Debug("Dropping response for cancelled request", "method", req.Method, "id", req.Id)
+		return nil
+	}
 	if err != nil {
 		var jsonErr *Error
 		if errors.As(err, &jsonErr) {
@@ -49,21 +121,60 @@ func (s *Server) HandleRequest(req *Request) *Response {
 	return NewSuccessResponse(result, req.Id)
 }
 
-func (s *Server) ServeStdio() error {
+func (s *Server) ServeStdio(ctx context.Context) error {
 	s.logger.Info("Starting server on stdio")
+	return s.Serve(ctx, os.Stdin, os.Stdout)
+}
 
-	reader := bufio.NewReader(os.Stdin)
-	writer := bufio.NewWriter(os.Stdout)
+// Serve handles newline-delimited requests from r until EOF or until ctx is
+// cancelled. On cancellation a read blocked on r is interrupted if r supports
+// read deadlines, as os.Stdin does for pipes; otherwise the reading goroutine
+// exits once r delivers its next line or is closed. Lines read after
+// cancellation are dropped.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+	writer := bufio.NewWriter(w)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	stop := make(chan struct{})
+	defer close(stop)
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case lines <- line:
+			case <-stop:
+				return
+			}
+		}
+	}()
 
 	for {
-		line, err := reader.ReadBytes('\n')
-		if err != nil {
+		var line []byte
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Context cancelled, shutting down server")
+			if d, ok := r.(interface{ SetReadDeadline(time.Time) error }); ok {
+				_ = d.SetReadDeadline(time.Now())
+			}
+			return nil
+		case err := <-readErr:
 			if err == io.EOF {
 				s.logger.Info("EOF received, shutting down server")
 				return nil
 			}
 			s.logger.Error("unable to read from stdin", "error", err)
 			return err
+		case line = <-lines:
 		}
 
 		s.logger.Debug("Received request", "request", string(line))
@@ -76,10 +187,27 @@ func (s *Server) ServeStdio() error {
 			continue
 		}
 
-		res := s.HandleRequest(&req)
-		if !req.IsNotification() {
-			s.writeResponse(writer, res)
+		// Notifications are handled inline so that ordering-sensitive ones
+		// (initialized, cancelled) take effect before the next message.
+		if req.IsNotification() {
+			s.HandleRequest(ctx, &req)
+			continue
 		}
+
+		if s.inline[req.Method] {
+			if res := s.HandleRequest(ctx, &req); res != nil {
+				s.writeResponse(writer, res)
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if res := s.HandleRequest(ctx, &req); res != nil {
+				s.writeResponse(writer, res)
+			}
+		}()
 	}
 }
 
@@ -91,6 +219,9 @@ func (s *Server) writeResponse(writer *bufio.Writer, res *Response) {
 	}
 	s.logger.Debug("Sending response", "response", string(bs))
 
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
 	_, _ = writer.Write(bs)
 	_ = writer.WriteByte('\n')
 	_ = writer.Flush()
@@ -99,6 +230,8 @@ func (s *Server) writeResponse(writer *bufio.Writer, res *Response) {
 func NewServer(logger *slog.Logger) *Server {
 	return &Server{
 		handlers: make(map[string]Handler),
+		inline:   make(map[string]bool),
 		logger:   logger,
+		inFlight: make(map[string]context.CancelCauseFunc),
 	}
 }
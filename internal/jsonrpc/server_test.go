@@ -0,0 +1,57 @@
+package jsonrpc
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+// pipeReader reports when a read fails, which is how the reading goroutine
+// ends.
+type pipeReader struct {
+	*os.File
+	failed chan error
+}
+
+func (p *pipeReader) Read(b []byte) (int, error) {
+	n, err := p.File.Read(b)
+	if err != nil {
+		p.failed <- err
+	}
+	return n, err
+}
+
+func TestServeStopsReadingOnCancel(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	server := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	reader := &pipeReader{File: r, failed: make(chan error, 1)}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(ctx, reader, io.Discard) }()
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("Serve returned %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Serve did not return after cancellation")
+	}
+
+	select {
+	case <-reader.failed:
+	case <-time.After(time.Second):
+		t.Fatal("the blocked read was not interrupted")
+	}
+}
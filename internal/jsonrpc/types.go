@@ -9,7 +9,7 @@ type Request struct {
 	JSONRPC string          `json:"jsonrpc"`
 	Method  string          `json:"method"`
 	Params  json.RawMessage `json:"params,omitempty"`
-	Id      any             `json:"id",omitempty`
+	Id      any             `json:"id,omitempty"`
 }
 
 type Response struct {
@@ -11,3 +11,23 @@ func NewToolCallError(message string) CallToolResult {
 		IsError: true,
 	}
 }
+
+// ReadOnly annotates a tool that does not change any state.
+func ReadOnly() *ToolAnnotations {
+	return &ToolAnnotations{ReadOnlyHint: hint(true)}
+}
+
+// Mutating annotates a tool that changes state. Destructive tools may
+// remove or overwrite data; calling an idempotent tool again with the same
+// arguments has no further effect.
+func Mutating(destructive, idempotent bool) *ToolAnnotations {
+	return &ToolAnnotations{
+		ReadOnlyHint:    hint(false),
+		DestructiveHint: hint(destructive),
+		IdempotentHint:  hint(idempotent),
+	}
+}
+
+func hint(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,54 @@
+package mcp
+
+const DefaultInstructions = `CartopherCopilot gives you access to the Cartopher store: its product catalog, the user's shopping cart and their orders.
+
+Recommended workflow:
+1. Find products with search_products (by keyword, price range or category) or browse with list_products.
+2. Use get_product_details to confirm price, stock and description before recommending an item.
+3. Add items with add_to_cart by product ID, SKU or name, or several at once with add_items_to_cart. If add_to_cart lists several candidates, ask the user to choose; never guess.
+4. Fix mistakes with update_cart_item, remove_from_cart or clear_cart; each returns the updated cart. If a cart change was wrong, undo_last_cart_change reverts it, and list_cart_snapshots with restore_cart_snapshot goes further back.
+5. Always call view_cart and show the user the contents and total before ordering, call validate_cart to catch stock or price problems early, and use estimate_checkout_total to show the total with shipping and tax.
+6. Only call place_order after the user has confirmed the cart. Never place an order with an empty cart.
+7. For questions about earlier purchases, find the order with list_orders (filter by status or date) and show its items, status and tracking with get_order_details.
+
+If the user prefers another currency, call set_currency once; prices marked ≈ are converted estimates and the store charges in its own currency.
+
+Use list_promotions to find promotion codes and apply_coupon to apply the ones the user asks for; view_cart shows the resulting discounts.
+
+When the user wants to buy something later, save it with add_to_wishlist; view_wishlist shows saved products with current prices and move_to_cart adds one to the cart.
+
+Cart and order tools act on the authenticated user's account. Ask before calling clear_cart, and treat place_order as irreversible and ask for explicit confirmation first.`
+
+type ServerMetadata struct {
+	Title        string
+	Description  string
+	WebsiteURL   string
+	Icons        []Icon
+	Instructions string
+}
+
+func (m ServerMetadata) serverInfo(features Features) ServerInfo {
+	info := ServerInfo{
+		Name:    ServerName,
+		Version: ServerVersion,
+	}
+
+	if features.ServerTitle {
+		info.Title = m.Title
+	}
+
+	if features.ServerMetadata {
+		info.Description = m.Description
+		info.WebsiteURL = m.WebsiteURL
+		info.Icons = m.Icons
+	}
+
+	return info
+}
+
+func (m ServerMetadata) instructions() string {
+	if m.Instructions == "" {
+		return DefaultInstructions
+	}
+	return m.Instructions
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"runtime/debug"
 )
 
 type ToolFunc func(ctx context.Context, args map[string]any) (CallToolResult, error)
@@ -20,6 +21,11 @@ func (r *Registry) Register(tool Tool, handler ToolFunc) {
 	r.logger.Debug("Registered tool", "tool", tool.Name)
 }
 
+func (r *Registry) Has(name string) bool {
+	_, ok := r.tools[name]
+	return ok
+}
+
 func (r *Registry) ListTools() []Tool {
 	tools := make([]Tool, 0, len(r.tools))
 	for _, tool := range r.tools {
@@ -28,12 +34,21 @@ func (r *Registry) ListTools() []Tool {
 	return tools
 }
 
-func (r *Registry) ExecuteTool(ctx context.Context, name string, args map[string]any) (CallToolResult, error) {
+// ExecuteTool runs the named tool. A panicking handler is turned into an
+// error, since handlers run on the server's dispatch goroutines and one bad
+// call must not take the whole server down.
+func (r *Registry) ExecuteTool(ctx context.Context, name string, args map[string]any) (result CallToolResult, err error) {
 	handler, ok := r.Handlers[name]
 	if !ok {
 		return CallToolResult{}, fmt.Errorf("tool not found: %s", name)
 	}
 
+	defer func() {
+		if p := recover(); p != nil {
+			r.logger.ErrorContext(ctx, "Tool panicked", "tool", name, "panic", p, "stack", string(debug.Stack()))
+			result, err = CallToolResult{}, fmt.Errorf("tool %s failed: %v", name, p)
+		}
+	}()
 	return handler(ctx, args)
 }
 
@@ -0,0 +1,21 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestExecuteToolRecoversFromPanic(t *testing.T) {
+	reg := NewRegistry(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	reg.Register(Tool{Name: "boom"}, func(context.Context, map[string]any) (CallToolResult, error) {
+		panic("mixed currencies")
+	})
+
+	_, err := reg.ExecuteTool(context.Background(), "boom", nil)
+	if err == nil || !strings.Contains(err.Error(), "mixed currencies") {
+		t.Fatalf("expected the panic as an error, got %v", err)
+	}
+}
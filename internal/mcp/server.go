@@ -4,59 +4,125 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/correlation"
 	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/jsonrpc"
 	"log/slog"
+	"slices"
+	"time"
 )
 
 const (
-	ProtocolVersion = "2025-11-25"
-	ServerName      = "CartopherCopilot"
-	ServerVersion   = "1.0.0"
+	ServerName    = "CartopherCopilot"
+	ServerVersion = "1.0.0"
 )
 
 type Server struct {
 	rpcServer    *jsonrpc.Server
 	toolRegistry *Registry
+	session      *Session
+	metadata     ServerMetadata
+	toolTimeout  time.Duration
+	errorHandler ErrorHandler
 	logger       *slog.Logger
 }
 
+type ErrorHandler func(err error) CallToolResult
+
+type Options struct {
+	Metadata        ServerMetadata
+	ToolCallTimeout time.Duration
+	ErrorHandler    ErrorHandler
+}
+
 func (s *Server) registerHandlers() {
-	s.rpcServer.RegisterMethod("initialize", s.handleInitialize)
+	s.rpcServer.RegisterInlineMethod("initialize", s.handleInitialize)
+	s.rpcServer.RegisterMethod("notifications/initialized", s.handleInitialized)
+	// Older clients send the pre-2024-11-05 method name.
 	s.rpcServer.RegisterMethod("initialized", s.handleInitialized)
-	s.rpcServer.RegisterMethod("tools/list", s.handleToolsList)
-	s.rpcServer.RegisterMethod("tools/call", s.handleToolsCall)
+	s.rpcServer.RegisterMethod("notifications/cancelled", s.handleCancelled)
+	s.rpcServer.RegisterMethod("ping", s.handlePing)
+	s.rpcServer.RegisterMethod("tools/list", s.requireInitialized(s.handleToolsList))
+	s.rpcServer.RegisterMethod("tools/call", s.requireInitialized(s.handleToolsCall))
 }
 
-func (s *Server) handleInitialize(params json.RawMessage) (any, error) {
+func (s *Server) requireInitialized(handler jsonrpc.Handler) jsonrpc.Handler {
+	return func(ctx context.Context, params json.RawMessage) (any, error) {
+		switch s.session.State() {
+		case StateUninitialized:
+			return nil, jsonrpc.NewServerNotInitializedError("initialize must be called first")
+		case StateInitializing:
+			return nil, jsonrpc.NewServerNotInitializedError("notifications/initialized must be sent first")
+		}
+		return handler(ctx, params)
+	}
+}
+
+func (s *Server) handleInitialize(_ context.Context, params json.RawMessage) (any, error) {
 	var req InitializeRequest
 	if err := json.Unmarshal(params, &req); err != nil {
 		return nil, jsonrpc.NewInvalidParamsError("Invalid initialize parameters")
 	}
 
-	s.logger.Info("Client initialized", "client", req.ClientInfo.Name, "clientVersion", req.ClientInfo.Version, "protocolVersion", req.ProtocolVersion)
+	version, ok := s.session.begin(req)
+	if !ok {
+		return nil, jsonrpc.NewInvalidRequestError("Server already initialized")
+	}
+
+	s.logger.Info("Client initialized", "client", req.ClientInfo.Name, "clientVersion", req.ClientInfo.Version, "requestedProtocolVersion", req.ProtocolVersion, "protocolVersion", version)
 
 	return InitializeResult{
-		ProtocolVersion: ProtocolVersion,
+		ProtocolVersion: version,
 		Capabilities: ServerCapabilities{
 			Tools: &ToolsCapability{
 				ListChanged: false,
 			},
 		},
-		ServerInfo: ServerInfo{
-			Name:    ServerName,
-			Version: ServerVersion,
-		},
+		ServerInfo:   s.metadata.serverInfo(s.session.Features()),
+		Instructions: s.metadata.instructions(),
 	}, nil
 }
 
-func (s *Server) handleInitialized(params json.RawMessage) (any, error) {
-	s.logger.Info("Initialization completed")
+func (s *Server) handleInitialized(_ context.Context, params json.RawMessage) (any, error) {
+	if !s.session.markReady() {
+		s.logger.Warn("Unexpected initialized notification", "state", s.session.State().String())
+		return nil, nil
+	}
+
+	s.logger.Info("Initialization completed", "protocolVersion", s.session.ProtocolVersion(), "features", s.session.Features())
+	return nil, nil
+}
+
+func (s *Server) handlePing(_ context.Context, params json.RawMessage) (any, error) {
+	return struct{}{}, nil
+}
+
+func (s *Server) handleCancelled(_ context.Context, params json.RawMessage) (any, error) {
+	var req CancelledNotification
+	if err := json.Unmarshal(params, &req); err != nil {
+		return nil, jsonrpc.NewInvalidParamsError("Invalid cancellation parameters")
+	}
+
+	if s.rpcServer.Cancel(req.RequestID) {
+		s.logger.Info("Request cancelled by client", "id", req.RequestID, "reason", req.Reason)
+	} else {
+		s.logger.Debug("Cancellation for unknown or completed request", "id", req.RequestID)
+	}
 	return nil, nil
 }
 
-func (s *Server) handleToolsList(params json.RawMessage) (any, error) {
+func (s *Server) handleToolsList(_ context.Context, params json.RawMessage) (any, error) {
 	tools := s.toolRegistry.ListTools()
 
+	features := s.session.Features()
+	for i := range tools {
+		if !features.ToolTitles {
+			tools[i].Title = ""
+		}
+		if !features.ToolAnnotations {
+			tools[i].Annotations = nil
+		}
+	}
+
 	s.logger.Debug("Listing tools", "count", len(tools))
 
 	return ToolsListResult{
@@ -64,44 +130,76 @@ func (s *Server) handleToolsList(params json.RawMessage) (any, error) {
 	}, nil
 }
 
-func (s *Server) handleToolsCall(params json.RawMessage) (interface{}, error) {
+func (s *Server) handleToolsCall(ctx context.Context, params json.RawMessage) (interface{}, error) {
 	var req CallToolRequest
 
 	if err := json.Unmarshal(params, &req); err != nil {
 		return nil, jsonrpc.NewInvalidParamsError("Invalid tool call parameters")
 	}
 
-	s.logger.Info("Calling tool", "tool", req.Name, "args", req.Arguments)
+	rpcID, _ := jsonrpc.RequestIDFromContext(ctx)
+	correlationID := correlation.Derive(s.session.ID(), rpcID)
+	ctx = correlation.WithID(ctx, correlationID)
 
-	ctx := context.Background()
+	s.logger.InfoContext(ctx, "Calling tool", "tool", req.Name, "args", req.Arguments)
+
+	if s.toolTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.toolTimeout)
+		defer cancel()
+	}
 
 	result, err := s.toolRegistry.ExecuteTool(ctx, req.Name, req.Arguments)
 	if err != nil {
-		s.logger.Error("Tool execution failed", "err", err)
-
-		return CallToolResult{
-			Content: []Content{
-				{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %s", err.Error()),
-				},
-			},
-			IsError: true,
-		}, nil
+		s.logger.ErrorContext(ctx, "Tool execution failed", "err", err)
+
+		result = s.errorHandler(err)
+	}
+
+	if result.IsError {
+		result = withCorrelationID(result, correlationID)
+	}
+	if !s.session.Features().StructuredContent {
+		result.StructuredContent = nil
 	}
 
 	return result, nil
 }
 
-func (s *Server) Start() error {
-	return s.rpcServer.ServeStdio()
+func withCorrelationID(result CallToolResult, id string) CallToolResult {
+	content := slices.Clone(result.Content)
+	for i := len(content) - 1; i >= 0; i-- {
+		if content[i].Type == "text" {
+			content[i].Text += "\n\nCorrelation ID: " + id
+			result.Content = content
+			return result
+		}
+	}
+	result.Content = append(content, Content{Type: "text", Text: "Correlation ID: " + id})
+	return result
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	return s.rpcServer.ServeStdio(ctx)
 }
 
-func NewServer(toolRegistry *Registry, logger *slog.Logger) *Server {
+func defaultErrorHandler(err error) CallToolResult {
+	return NewToolCallError(fmt.Sprintf("Error: %s", err.Error()))
+}
+
+func NewServer(toolRegistry *Registry, opts Options, logger *slog.Logger) *Server {
+	if opts.ErrorHandler == nil {
+		opts.ErrorHandler = defaultErrorHandler
+	}
+
 	rpcServer := jsonrpc.NewServer(logger)
 	server := &Server{
 		rpcServer:    rpcServer,
 		toolRegistry: toolRegistry,
+		session:      NewSession(),
+		metadata:     opts.Metadata,
+		toolTimeout:  opts.ToolCallTimeout,
+		errorHandler: opts.ErrorHandler,
 		logger:       logger,
 	}
 	server.registerHandlers()
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/jsonrpc"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestToolsCallWaitsForInitialized(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	reg := NewRegistry(logger)
+	reg.Register(Tool{Name: "echo"}, func(context.Context, map[string]any) (CallToolResult, error) {
+		return CallToolResult{Content: []Content{{Type: "text", Text: "ok"}}}, nil
+	})
+	server := NewServer(reg, Options{}, logger)
+
+	call := func(method, params string) *jsonrpc.Response {
+		t.Helper()
+		return server.rpcServer.HandleRequest(t.Context(), &jsonrpc.Request{JSONRPC: "2.0", Method: method, Params: json.RawMessage(params), Id: 1})
+	}
+
+	if res := call("tools/call", `{"name":"echo"}`); res.Error == nil {
+		t.Fatalf("expected tools/call before initialize to fail, got %+v", res)
+	}
+	if res := call("initialize", `{"protocolVersion":"2025-06-18","clientInfo":{"name":"test"}}`); res.Error != nil {
+		t.Fatalf("initialize failed: %+v", res.Error)
+	}
+	if res := call("tools/call", `{"name":"echo"}`); res.Error == nil {
+		t.Fatalf("expected tools/call before notifications/initialized to fail, got %+v", res)
+	}
+
+	server.rpcServer.HandleRequest(t.Context(), &jsonrpc.Request{JSONRPC: "2.0", Method: "notifications/initialized"})
+	if res := call("tools/call", `{"name":"echo"}`); res.Error != nil {
+		t.Fatalf("expected tools/call to succeed once ready, got %+v", res.Error)
+	}
+}
+
+func TestToolsListFollowsProtocolVersion(t *testing.T) {
+	for _, tc := range []struct {
+		version           string
+		title, annotation bool
+	}{
+		{"2024-11-05", false, false},
+		{"2025-03-26", false, true},
+		{"2025-06-18", true, true},
+	} {
+		t.Run(tc.version, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			reg := NewRegistry(logger)
+			reg.Register(Tool{Name: "view_cart", Title: "View Cart", Annotations: ReadOnly()}, nil)
+			server := NewServer(reg, Options{}, logger)
+
+			server.rpcServer.HandleRequest(t.Context(), &jsonrpc.Request{JSONRPC: "2.0", Method: "initialize", Params: json.RawMessage(`{"protocolVersion":"` + tc.version + `","clientInfo":{"name":"test"}}`), Id: 1})
+			server.rpcServer.HandleRequest(t.Context(), &jsonrpc.Request{JSONRPC: "2.0", Method: "notifications/initialized"})
+			res := server.rpcServer.HandleRequest(t.Context(), &jsonrpc.Request{JSONRPC: "2.0", Method: "tools/list", Id: 2})
+			if res.Error != nil {
+				t.Fatal(res.Error)
+			}
+
+			tool := res.Result.(ToolsListResult).Tools[0]
+			if (tool.Title != "") != tc.title {
+				t.Errorf("title = %q, want present %v", tool.Title, tc.title)
+			}
+			if (tool.Annotations != nil) != tc.annotation {
+				t.Errorf("annotations = %+v, want present %v", tool.Annotations, tc.annotation)
+			}
+		})
+	}
+}
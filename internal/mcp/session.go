@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/correlation"
+	"slices"
+	"sync"
+)
+
+var SupportedProtocolVersions = []string{
+	"2025-11-25",
+	"2025-06-18",
+	"2025-03-26",
+	"2024-11-05",
+}
+
+type SessionState int
+
+const (
+	StateUninitialized SessionState = iota
+	StateInitializing
+	StateReady
+)
+
+func (s SessionState) String() string {
+	switch s {
+	case StateUninitialized:
+		return "uninitialized"
+	case StateInitializing:
+		return "initializing"
+	case StateReady:
+		return "ready"
+	default:
+		return "unknown"
+	}
+}
+
+type Features struct {
+	ServerTitle       bool
+	ServerMetadata    bool
+	ToolTitles        bool
+	ToolAnnotations   bool
+	StructuredContent bool
+}
+
+type Session struct {
+	id                 string
+	mu                 sync.RWMutex
+	state              SessionState
+	protocolVersion    string
+	clientInfo         ClientInfo
+	clientCapabilities ClientCapabilities
+	features           Features
+}
+
+func (s *Session) ID() string {
+	return s.id
+}
+
+func (s *Session) State() SessionState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+func (s *Session) ProtocolVersion() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.protocolVersion
+}
+
+func (s *Session) ClientInfo() ClientInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.clientInfo
+}
+
+func (s *Session) Features() Features {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.features
+}
+
+func (s *Session) begin(req InitializeRequest) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != StateUninitialized {
+		return "", false
+	}
+
+	s.protocolVersion = NegotiateProtocolVersion(req.ProtocolVersion)
+	s.clientInfo = req.ClientInfo
+	s.clientCapabilities = req.Capabilities
+	s.features = resolveFeatures(s.protocolVersion)
+	s.state = StateInitializing
+
+	return s.protocolVersion, true
+}
+
+func (s *Session) markReady() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != StateInitializing {
+		return false
+	}
+
+	s.state = StateReady
+	return true
+}
+
+func NegotiateProtocolVersion(requested string) string {
+	if slices.Contains(SupportedProtocolVersions, requested) {
+		return requested
+	}
+	return SupportedProtocolVersions[0]
+}
+
+// Protocol versions are ISO dates, so lexical order matches release order.
+func versionAtLeast(version, minimum string) bool {
+	return version >= minimum
+}
+
+func resolveFeatures(version string) Features {
+	return Features{
+		ServerTitle:       versionAtLeast(version, "2025-06-18"),
+		ServerMetadata:    versionAtLeast(version, "2025-11-25"),
+		ToolTitles:        versionAtLeast(version, "2025-06-18"),
+		ToolAnnotations:   versionAtLeast(version, "2025-03-26"),
+		StructuredContent: versionAtLeast(version, "2025-06-18"),
+	}
+}
+
+func NewSession() *Session {
+	return &Session{
+		id:    correlation.NewID(),
+		state: StateUninitialized,
+	}
+}
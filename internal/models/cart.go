@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type Cart struct {
+	Id        int        `json:"id"`
+	UserId    int        `json:"user_id"`
+	CartItems []CartItem `json:"cart_items"`
+	// Total is the sum of the line subtotals; Discounts are applied on top.
+	Total     float64    `json:"total"`
+	Discounts []Discount `json:"discounts,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+type CartItem struct {
+	Id       int     `json:"id"`
+	Product  Product `json:"product"`
+	Quantity int     `json:"quantity"`
+	// Price is the unit price when the line was added; Subtotal follows the
+	// current catalog price.
+	Price     float64   `json:"price,omitempty"`
+	Subtotal  float64   `json:"subtotal"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+
+// Backends report amounts as decimals in the store currency. These convert
+// them to minor units once, so nothing downstream does arithmetic on floats.
+
+func (c *Cart) TotalIn(currency string) money.Money {
+	return money.FromFloat(c.Total, currency)
+}
+
+func (i CartItem) PriceIn(currency string) money.Money {
+	return money.FromFloat(i.Price, currency)
+}
+
+func (i CartItem) SubtotalIn(currency string) money.Money {
+	return money.FromFloat(i.Subtotal, currency)
+}
+
+func (p Product) PriceIn(currency string) money.Money {
+	return money.FromFloat(p.Price, currency)
+}
+
+func (d Discount) AmountIn(currency string) money.Money {
+	return money.FromFloat(d.Amount, currency)
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+const (
+	OrderPending    = "pending"
+	OrderPaid       = "paid"
+	OrderProcessing = "processing"
+	OrderShipped    = "shipped"
+	OrderDelivered  = "delivered"
+	OrderCancelled  = "cancelled"
+)
+
+type Order struct {
+	Id             int                `json:"id"`
+	UserId         int                `json:"user_id"`
+	Status         string             `json:"status"`
+	Subtotal       float64            `json:"subtotal"`
+	DiscountAmount float64            `json:"discount_amount"`
+	ShippingCost   float64            `json:"shipping_cost"`
+	TaxAmount      float64            `json:"tax_amount"`
+	Total          float64            `json:"total_amount"`
+	CouponCodes    []string           `json:"coupon_codes,omitempty"`
+	OrderItems     []OrderItem        `json:"order_items"`
+	StatusHistory  []OrderStatusEvent `json:"status_history,omitempty"`
+	Shipping       *ShippingInfo      `json:"shipping,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+type OrderItem struct {
+	Id       int     `json:"id"`
+	Product  Product `json:"product"`
+	Quantity int     `json:"quantity"`
+	// Price is the unit price charged when the order was placed.
+	Price    float64 `json:"price"`
+	Subtotal float64 `json:"subtotal"`
+}
+
+type OrderStatusEvent struct {
+	Status    string    `json:"status"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ShippingInfo struct {
+	Method            string     `json:"method,omitempty"`
+	Carrier           string     `json:"carrier,omitempty"`
+	TrackingNumber    string     `json:"tracking_number,omitempty"`
+	TrackingURL       string     `json:"tracking_url,omitempty"`
+	Address           *Address   `json:"address,omitempty"`
+	ShippedAt         *time.Time `json:"shipped_at,omitempty"`
+	EstimatedDelivery *time.Time `json:"estimated_delivery,omitempty"`
+	DeliveredAt       *time.Time `json:"delivered_at,omitempty"`
+}
+
+type Address struct {
+	Name       string `json:"name,omitempty"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+}
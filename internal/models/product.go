@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+type Product struct {
+	Id          int     `json:"id"`
+	CategoryId  int     `json:"category_id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+	Stock       int     `json:"stock"`
+	Sku         string  `json:"sku"`
+	// Weight is in kilograms; zero when the backend does not report it.
+	Weight    float64   `json:"weight,omitempty"`
+	IsActive  bool      `json:"is_active"`
+	Category  Category  `json:"category"`
+	Images    []Image   `json:"images"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Category struct {
+	Id          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type Image struct {
+	Id        int       `json:"id"`
+	Url       string    `json:"url"`
+	AltText   string    `json:"alt_text"`
+	IsPrimary bool      `json:"is_primary"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+const (
+	PromotionPercentage  = "percentage"
+	PromotionFixedAmount = "fixed_amount"
+)
+
+type Promotion struct {
+	Code        string     `json:"code"`
+	Description string     `json:"description"`
+	Type        string     `json:"type"`
+	Value       float64    `json:"value"`
+	CategoryId  int        `json:"category_id,omitempty"`
+	MinSubtotal float64    `json:"min_subtotal,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+type Discount struct {
+	Code        string  `json:"code"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
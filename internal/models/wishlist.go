@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type Wishlist struct {
+	Items []WishlistItem `json:"items"`
+}
+
+type WishlistItem struct {
+	Id        int       `json:"id"`
+	Product   Product   `json:"product"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,143 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Display holds the session's preferred currency. Amounts from the store are
+// always in its own currency and are only converted for display.
+type Display struct {
+	mu       sync.Mutex
+	store    string
+	rates    *Rates
+	currency string
+}
+
+func (d *Display) Store() string {
+	return d.store
+}
+
+func (d *Display) Currency() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.currency
+}
+
+func (d *Display) Currencies() []string {
+	return d.rates.Currencies()
+}
+
+func (d *Display) SetCurrency(code string) error {
+	code, err := d.check(code)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.currency = code
+	return nil
+}
+
+// Formatter formats amounts in code, or in the session currency when code is
+// empty.
+func (d *Display) Formatter(code string) (Formatter, error) {
+	if strings.TrimSpace(code) == "" {
+		code = d.Currency()
+	}
+	code, err := d.check(code)
+	if err != nil {
+		return Formatter{}, err
+	}
+	return Formatter{store: d.store, currency: code, rates: d.rates}, nil
+}
+
+func (d *Display) check(code string) (string, error) {
+	code, err := Normalize(code)
+	if err != nil {
+		return "", err
+	}
+	if code != d.store && !d.rates.Has(code) {
+		return "", fmt.Errorf("%w %s; available: %s", ErrUnknownCurrency, code, strings.Join(d.Currencies(), ", "))
+	}
+	return code, nil
+}
+
+// Formatter renders store amounts in one display currency. Converted amounts
+// are prefixed with ≈ and Note explains where they come from.
+type Formatter struct {
+	store    string
+	currency string
+	rates    *Rates
+}
+
+func (f Formatter) Converted() bool {
+	return f.currency != f.store
+}
+
+// Store turns a backend amount into money in the store currency.
+func (f Formatter) Store(v float64) Money {
+	return FromFloat(v, f.store)
+}
+
+func (f Formatter) Format(v float64) string {
+	return f.FormatMoney(f.Store(v))
+}
+
+func (f Formatter) FormatMoney(m Money) string {
+	if !f.Converted() {
+		return m.String()
+	}
+	converted, err := f.rates.Convert(m, f.currency)
+	if err != nil {
+		return m.String()
+	}
+	return "≈" + converted.String()
+}
+
+// Charged formats an amount the store actually charges, keeping the store
+// amount next to the estimate.
+func (f Formatter) Charged(v float64) string {
+	m := f.Store(v)
+	if !f.Converted() {
+		return m.String()
+	}
+	return fmt.Sprintf("%s (charged as %s)", f.FormatMoney(m), m)
+}
+
+// Note is appended to output with converted amounts; it is empty otherwise.
+func (f Formatter) Note() string {
+	if !f.Converted() {
+		return ""
+	}
+	rate, err := f.rates.Rate(f.store, f.currency)
+	if err != nil {
+		return ""
+	}
+	asOf := ""
+	if f.rates.AsOf != "" {
+		asOf = fmt.Sprintf(" as of %s", f.rates.AsOf)
+	}
+	return fmt.Sprintf("\n\n≈ Estimated in %s at 1 %s = %.4f %s (local exchange-rate table%s). The store charges in %s.",
+		f.currency, f.store, rate, f.currency, asOf, f.store)
+}
+
+// NewDisplay starts the session in the store currency. rates may be nil when
+// no conversion is configured.
+func NewDisplay(store string, rates *Rates) (*Display, error) {
+	store, err := Normalize(store)
+	if err != nil {
+		return nil, fmt.Errorf("store currency: %w", err)
+	}
+	if rates == nil {
+		rates, _ = NewRates(store, "", nil)
+	}
+	if !rates.Has(store) {
+		return nil, fmt.Errorf("exchange rates have no rate for the store currency %s", store)
+	}
+	return &Display{store: store, rates: rates, currency: store}, nil
+}
@@ -0,0 +1,183 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+const USD = "USD"
+
+type currency struct {
+	symbol   string
+	exponent int
+}
+
+var currencies = map[string]currency{
+	"USD": {symbol: "$", exponent: 2},
+	"EUR": {symbol: "€", exponent: 2},
+	"GBP": {symbol: "£", exponent: 2},
+	"CAD": {symbol: "CA$", exponent: 2},
+	"AUD": {symbol: "A$", exponent: 2},
+	"CHF": {symbol: "CHF ", exponent: 2},
+	"JPY": {symbol: "¥", exponent: 0},
+}
+
+func lookup(code string) currency {
+	if c, ok := currencies[code]; ok {
+		return c
+	}
+	return currency{symbol: code + " ", exponent: 2}
+}
+
+// Normalize upper-cases a currency code and checks that it looks like an
+// ISO 4217 code.
+func Normalize(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return "", fmt.Errorf("%w %q", ErrUnknownCurrency, code)
+	}
+	return code, nil
+}
+
+// Money is an amount in the minor units of its currency, such as cents.
+// Arithmetic on amounts in different currencies is a programming error and
+// panics; convert with Rates first.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// FromFloat converts a decimal amount as the backend reports it, rounding to
+// the currency's minor unit.
+func FromFloat(v float64, code string) Money {
+	scale := math.Pow10(lookup(code).exponent)
+	return Money{Amount: int64(math.Round(v * scale)), Currency: code}
+}
+
+func (m Money) Float() float64 {
+	return float64(m.Amount) / math.Pow10(lookup(m.Currency).exponent)
+}
+
+// Zero is no money in code, the starting point for sums.
+func Zero(code string) Money {
+	return Money{Currency: code}
+}
+
+func (m Money) Add(o Money) Money {
+	m.same(o)
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}
+}
+
+func (m Money) Sub(o Money) Money {
+	m.same(o)
+	return Money{Amount: m.Amount - o.Amount, Currency: m.Currency}
+}
+
+func (m Money) Mul(n int) Money {
+	return Money{Amount: m.Amount * int64(n), Currency: m.Currency}
+}
+
+// MulDiv scales the amount by num/den, rounding half away from zero, such as
+// to spread a discount over cart lines in proportion to their subtotal.
+func (m Money) MulDiv(num, den int64) Money {
+	if den == 0 {
+		panic("money: division by zero")
+	}
+	return Money{Amount: divRound(m.Amount*num, den), Currency: m.Currency}
+}
+
+// Percent returns pct percent of the amount, rounded half away from zero to
+// the minor unit.
+func (m Money) Percent(pct float64) Money {
+	return Money{Amount: int64(math.Round(float64(m.Amount) * pct / 100)), Currency: m.Currency}
+}
+
+// Cmp returns -1, 0 or +1 as m is less than, equal to or greater than o.
+func (m Money) Cmp(o Money) int {
+	m.same(o)
+	switch {
+	case m.Amount < o.Amount:
+		return -1
+	case m.Amount > o.Amount:
+		return 1
+	}
+	return 0
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) same(o Money) {
+	if m.Currency != o.Currency {
+		panic(fmt.Sprintf("money: mixing %s and %s", m.Currency, o.Currency))
+	}
+}
+
+func Min(a, b Money) Money {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+func Max(a, b Money) Money {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+func divRound(n, d int64) int64 {
+	if (n < 0) != (d < 0) {
+		return (n - d/2) / d
+	}
+	return (n + d/2) / d
+}
+
+// String formats the amount with the currency symbol and thousands
+// separators, e.g. $1,234.50 or -£5.00.
+func (m Money) String() string {
+	c := lookup(m.Currency)
+
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	scale := int64(math.Pow10(c.exponent))
+	units := group(strconv.FormatInt(amount/scale, 10))
+	if c.exponent == 0 {
+		return sign + c.symbol + units
+	}
+	return fmt.Sprintf("%s%s%s.%0*d", sign, c.symbol, units, c.exponent, amount%scale)
+}
+
+func group(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
@@ -0,0 +1,25 @@
+package money
+
+import "testing"
+
+func TestArithmeticStaysInMinorUnits(t *testing.T) {
+	a := FromFloat(0.1, USD).Add(FromFloat(0.2, USD))
+	if a.Amount != 30 || a.String() != "$0.30" {
+		t.Errorf("expected $0.30, got %s (%d)", a, a.Amount)
+	}
+	if got := FromFloat(41.90, USD).Percent(5); got.Amount != 210 {
+		t.Errorf("expected 5%% of $41.90 to be 210 cents, got %d", got.Amount)
+	}
+	if got := FromFloat(-0.05, USD).MulDiv(1, 2); got.Amount != -3 {
+		t.Errorf("expected half of -5 cents to round away from zero, got %d", got.Amount)
+	}
+}
+
+func TestMixingCurrenciesPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected adding EUR to USD to panic")
+		}
+	}()
+	FromFloat(1, USD).Add(FromFloat(1, "EUR"))
+}
@@ -0,0 +1,104 @@
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Rates is an exchange-rate table: how much of each currency one unit of Base
+// buys. It is loaded from a file, so converted amounts are only estimates.
+type Rates struct {
+	Base  string             `json:"base"`
+	AsOf  string             `json:"as_of"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+func (r *Rates) rate(code string) (float64, bool) {
+	if code == r.Base {
+		return 1, true
+	}
+	rate, ok := r.Rates[code]
+	return rate, ok
+}
+
+func (r *Rates) Has(code string) bool {
+	_, ok := r.rate(code)
+	return ok
+}
+
+func (r *Rates) Currencies() []string {
+	codes := []string{r.Base}
+	for code := range r.Rates {
+		if code != r.Base {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes[1:])
+	return codes
+}
+
+// Rate returns how much of to one unit of from buys.
+func (r *Rates) Rate(from, to string) (float64, error) {
+	fromRate, ok := r.rate(from)
+	if !ok {
+		return 0, fmt.Errorf("%w %s", ErrUnknownCurrency, from)
+	}
+	toRate, ok := r.rate(to)
+	if !ok {
+		return 0, fmt.Errorf("%w %s", ErrUnknownCurrency, to)
+	}
+	return toRate / fromRate, nil
+}
+
+func (r *Rates) Convert(m Money, to string) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+	rate, err := r.Rate(m.Currency, to)
+	if err != nil {
+		return Money{}, err
+	}
+	return FromFloat(m.Float()*rate, to), nil
+}
+
+// LoadRates reads an exchange-rate table from a JSON file. An empty path
+// yields a table that only knows base.
+func LoadRates(path, base string) (*Rates, error) {
+	if path == "" {
+		return NewRates(base, "", nil)
+	}
+
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rates Rates
+	if err := json.Unmarshal(bs, &rates); err != nil {
+		return nil, fmt.Errorf("invalid exchange rates file %s: %w", path, err)
+	}
+
+	return NewRates(rates.Base, rates.AsOf, rates.Rates)
+}
+
+func NewRates(base, asOf string, rates map[string]float64) (*Rates, error) {
+	base, err := Normalize(base)
+	if err != nil {
+		return nil, fmt.Errorf("exchange rates base: %w", err)
+	}
+
+	r := &Rates{Base: base, AsOf: asOf, Rates: make(map[string]float64, len(rates))}
+	for code, rate := range rates {
+		normalized, err := Normalize(code)
+		if err != nil {
+			return nil, fmt.Errorf("exchange rates: %w", err)
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("exchange rate for %s must be positive", normalized)
+		}
+		r.Rates[normalized] = rate
+	}
+	return r, nil
+}
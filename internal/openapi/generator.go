@@ -0,0 +1,474 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/client"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/correlation"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Filter struct {
+	// Include and Exclude hold glob patterns matched against an operation's
+	// operationId, "METHOD /path" and "tag:<name>" forms.
+	Include []string
+	Exclude []string
+	Renames map[string]string
+	Prefix  string
+}
+
+func (f Filter) selects(method, route string, op *Operation) bool {
+	keys := []string{op.OperationID, method + " " + route}
+	for _, tag := range op.Tags {
+		keys = append(keys, "tag:"+tag)
+	}
+
+	if matchAny(f.Exclude, keys) {
+		return false
+	}
+	if len(f.Include) == 0 {
+		return !op.Deprecated
+	}
+	return matchAny(f.Include, keys)
+}
+
+func matchAny(patterns, keys []string) bool {
+	for _, pattern := range patterns {
+		for _, key := range keys {
+			if key == "" {
+				continue
+			}
+			if ok, _ := path.Match(pattern, key); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reservedHeaders are set by the client itself or by the transport, and are
+// never exposed as tool arguments.
+var reservedHeaders = []string{
+	"Accept",
+	"Authorization",
+	"Connection",
+	"Content-Length",
+	"Content-Type",
+	"Cookie",
+	"Host",
+	"If-None-Match",
+	"Keep-Alive",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"TE",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+	client.IdempotencyKeyHeader,
+	correlation.Header,
+}
+
+func reservedHeader(name string) bool {
+	return slices.ContainsFunc(reservedHeaders, func(h string) bool {
+		return strings.EqualFold(h, name)
+	})
+}
+
+type Generator struct {
+	doc        *Document
+	restClient *client.RestClient
+	filter     Filter
+	logger     *slog.Logger
+}
+
+// Register adds one tool per selected operation. Operations whose tool name
+// is already taken are skipped so that hand-written tools always win.
+func (g *Generator) Register(ctx context.Context, reg *mcp.Registry) int {
+	routes := make([]string, 0, len(g.doc.Paths))
+	for route := range g.doc.Paths {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	registered := 0
+	for _, route := range routes {
+		item := g.doc.Paths[route]
+		ops := item.operations()
+
+		methods := make([]string, 0, len(ops))
+		for method := range ops {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			op := ops[method]
+			if !g.filter.selects(method, route, op) {
+				continue
+			}
+
+			tool, handler, err := g.buildTool(ctx, method, route, item.Parameters, op)
+			if err != nil {
+				g.logger.WarnContext(ctx, "Skipping OpenAPI operation", "method", method, "path", route, "error", err)
+				continue
+			}
+
+			if reg.Has(tool.Name) {
+				g.logger.InfoContext(ctx, "Skipping OpenAPI operation, tool already registered", "tool", tool.Name, "method", method, "path", route)
+				continue
+			}
+
+			reg.Register(tool, handler.handle)
+			registered++
+		}
+	}
+
+	return registered
+}
+
+func (g *Generator) buildTool(ctx context.Context, method, route string, shared []*Parameter, op *Operation) (mcp.Tool, *operationHandler, error) {
+	handler := &operationHandler{
+		method:     method,
+		route:      route,
+		secured:    g.secured(op),
+		restClient: g.restClient,
+		bodyFields: make(map[string]string),
+	}
+
+	schema := mcp.InputSchema{
+		Type:       "object",
+		Properties: make(map[string]mcp.Property),
+	}
+
+	params := make(map[string]*Parameter)
+	for _, p := range append(append([]*Parameter{}, shared...), op.Parameters...) {
+		resolved, err := g.doc.resolveParameter(p)
+		if err != nil {
+			return mcp.Tool{}, nil, err
+		}
+		params[resolved.In+":"+resolved.Name] = resolved
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		p := params[key]
+		required := p.Required
+		switch p.In {
+		case "path":
+			handler.pathParams = append(handler.pathParams, p.Name)
+			required = true
+		case "query":
+			handler.queryParams = append(handler.queryParams, p.Name)
+		case "header":
+			if reservedHeader(p.Name) {
+				g.logger.DebugContext(ctx, "Not exposing reserved header parameter", "method", method, "path", route, "header", p.Name)
+				continue
+			}
+			handler.headerParams = append(handler.headerParams, p.Name)
+		default:
+			continue
+		}
+
+		schema.Properties[p.Name] = g.property(p.Schema, p.Description)
+		if required {
+			schema.Required = append(schema.Required, p.Name)
+		}
+	}
+
+	body, err := g.doc.resolveRequestBody(op.RequestBody)
+	if err != nil {
+		return mcp.Tool{}, nil, err
+	}
+	if body != nil {
+		if err := g.addBody(handler, &schema, body); err != nil {
+			return mcp.Tool{}, nil, err
+		}
+	}
+
+	handler.required = schema.Required
+
+	return mcp.Tool{
+		Name:        g.toolName(method, route, op),
+		Title:       strings.TrimSuffix(op.Summary, "."),
+		Description: g.description(method, route, op, handler.secured),
+		InputSchema: schema,
+		Annotations: annotations(method),
+	}, handler, nil
+}
+
+// annotations derives tool hints from the HTTP method semantics.
+func annotations(method string) *mcp.ToolAnnotations {
+	switch method {
+	case http.MethodGet:
+		return mcp.ReadOnly()
+	case http.MethodPut, http.MethodDelete:
+		return mcp.Mutating(true, true)
+	default:
+		return mcp.Mutating(false, false)
+	}
+}
+
+// addBody exposes the properties of a JSON object body as top-level tool
+// arguments. Anything else is passed through a single "body" argument.
+func (g *Generator) addBody(handler *operationHandler, schema *mcp.InputSchema, body *RequestBody) error {
+	media, ok := body.Content["application/json"]
+	if !ok {
+		return fmt.Errorf("request body has no application/json content")
+	}
+
+	bodySchema := g.doc.resolveSchema(media.Schema)
+	if bodySchema == nil || (bodySchema.Type != "object" && len(bodySchema.Properties) == 0) {
+		handler.rawBody = true
+		schema.Properties["body"] = g.property(bodySchema, body.Description)
+		if body.Required {
+			schema.Required = append(schema.Required, "body")
+		}
+		return nil
+	}
+
+	required := make(map[string]bool, len(bodySchema.Required))
+	for _, name := range bodySchema.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(bodySchema.Properties))
+	for name := range bodySchema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		arg := name
+		if _, taken := schema.Properties[arg]; taken {
+			arg = "body_" + name
+		}
+		handler.bodyFields[arg] = name
+		schema.Properties[arg] = g.property(bodySchema.Properties[name], "")
+		if body.Required && required[name] {
+			schema.Required = append(schema.Required, arg)
+		}
+	}
+	return nil
+}
+
+func (g *Generator) property(s *Schema, description string) mcp.Property {
+	s = g.doc.resolveSchema(s)
+	if s == nil {
+		return mcp.Property{Type: "string", Description: description}
+	}
+
+	if description == "" {
+		description = s.Description
+	}
+
+	prop := mcp.Property{
+		Type:        s.Type,
+		Description: description,
+	}
+	if prop.Type == "" {
+		prop.Type = "string"
+	}
+
+	for _, v := range s.Enum {
+		prop.Enum = append(prop.Enum, fmt.Sprint(v))
+	}
+
+	if s.Type == "array" {
+		itemType := "string"
+		if items := g.doc.resolveSchema(s.Items); items != nil && items.Type != "" {
+			itemType = items.Type
+		}
+		prop.Items = &mcp.Items{Type: itemType}
+	}
+
+	return prop
+}
+
+func (g *Generator) secured(op *Operation) bool {
+	if op.Security != nil {
+		return len(*op.Security) > 0
+	}
+	return len(g.doc.Security) > 0
+}
+
+func (g *Generator) toolName(method, route string, op *Operation) string {
+	name := op.OperationID
+	if name == "" {
+		name = strings.ToLower(method) + "_" + route
+	}
+	name = snakeCase(name)
+
+	if renamed, ok := g.filter.Renames[name]; ok {
+		return renamed
+	}
+	if renamed, ok := g.filter.Renames[op.OperationID]; ok && op.OperationID != "" {
+		return renamed
+	}
+	return g.filter.Prefix + name
+}
+
+func (g *Generator) description(method, route string, op *Operation, secured bool) string {
+	parts := make([]string, 0, 3)
+	if op.Summary != "" {
+		parts = append(parts, strings.TrimSuffix(op.Summary, "."))
+	}
+	if op.Description != "" && op.Description != op.Summary {
+		parts = append(parts, strings.TrimSuffix(op.Description, "."))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("Call %s %s on the Cartopher API", method, route))
+	}
+
+	description := strings.Join(parts, ". ")
+	if secured {
+		description += " (requires authentication)"
+	}
+	return description
+}
+
+var (
+	camelBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	nonWord       = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+)
+
+func snakeCase(s string) string {
+	s = camelBoundary.ReplaceAllString(s, "${1}_${2}")
+	s = nonWord.ReplaceAllString(s, "_")
+	return strings.Trim(strings.ToLower(s), "_")
+}
+
+type operationHandler struct {
+	method       string
+	route        string
+	secured      bool
+	pathParams   []string
+	queryParams  []string
+	headerParams []string
+	bodyFields   map[string]string
+	rawBody      bool
+	required     []string
+	restClient   *client.RestClient
+}
+
+func (h *operationHandler) handle(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	for _, name := range h.required {
+		if _, ok := args[name]; !ok {
+			return mcp.NewToolCallError(fmt.Sprintf("Missing required argument: %s", name)), nil
+		}
+	}
+
+	req := client.Request{
+		Method:  h.method,
+		Path:    h.route,
+		Query:   make(map[string]string),
+		Headers: make(map[string]string),
+	}
+
+	for _, name := range h.pathParams {
+		req.Path = strings.ReplaceAll(req.Path, "{"+name+"}", url.PathEscape(stringify(args[name])))
+	}
+	for _, name := range h.queryParams {
+		if v, ok := args[name]; ok {
+			req.Query[name] = stringify(v)
+		}
+	}
+	for _, name := range h.headerParams {
+		if v, ok := args[name]; ok {
+			req.Headers[name] = stringify(v)
+		}
+	}
+
+	if h.rawBody {
+		req.Body = args["body"]
+	} else if len(h.bodyFields) > 0 {
+		body := make(map[string]any)
+		for arg, field := range h.bodyFields {
+			if v, ok := args[arg]; ok {
+				body[field] = v
+			}
+		}
+		req.Body = body
+	}
+
+	rc := h.restClient
+	if h.secured {
+		rc = rc.WithToken()
+	}
+
+	response, err := rc.Do(ctx, req)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("%s %s failed: %w", h.method, h.route, err)
+	}
+
+	return mcp.CallToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: formatResponse(response),
+			},
+		},
+	}, nil
+}
+
+func stringify(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	case []any:
+		parts := make([]string, 0, len(value))
+		for _, item := range value {
+			parts = append(parts, stringify(item))
+		}
+		return strings.Join(parts, ",")
+	default:
+		bs, _ := json.Marshal(value)
+		return string(bs)
+	}
+}
+
+func formatResponse(response []byte) string {
+	if len(response) == 0 {
+		return "✓ Request completed"
+	}
+
+	var v any
+	if err := json.Unmarshal(response, &v); err != nil {
+		return string(response)
+	}
+
+	pretty, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return string(response)
+	}
+	return string(pretty)
+}
+
+func NewGenerator(doc *Document, restClient *client.RestClient, filter Filter, logger *slog.Logger) *Generator {
+	return &Generator{
+		doc:        doc,
+		restClient: restClient,
+		filter:     filter,
+		logger:     logger,
+	}
+}
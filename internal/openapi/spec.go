@@ -0,0 +1,215 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []SecurityRequirement `json:"security"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas       map[string]*Schema      `json:"schemas"`
+	Parameters    map[string]*Parameter   `json:"parameters"`
+	RequestBodies map[string]*RequestBody `json:"requestBodies"`
+}
+
+type SecurityRequirement map[string][]string
+
+type PathItem struct {
+	Parameters []*Parameter `json:"parameters"`
+	Get        *Operation   `json:"get"`
+	Put        *Operation   `json:"put"`
+	Post       *Operation   `json:"post"`
+	Delete     *Operation   `json:"delete"`
+	Patch      *Operation   `json:"patch"`
+}
+
+func (p PathItem) operations() map[string]*Operation {
+	ops := map[string]*Operation{
+		http.MethodGet:    p.Get,
+		http.MethodPut:    p.Put,
+		http.MethodPost:   p.Post,
+		http.MethodDelete: p.Delete,
+		http.MethodPatch:  p.Patch,
+	}
+	for method, op := range ops {
+		if op == nil {
+			delete(ops, method)
+		}
+	}
+	return ops
+}
+
+type Operation struct {
+	OperationID string                 `json:"operationId"`
+	Summary     string                 `json:"summary"`
+	Description string                 `json:"description"`
+	Tags        []string               `json:"tags"`
+	Parameters  []*Parameter           `json:"parameters"`
+	RequestBody *RequestBody           `json:"requestBody"`
+	Security    *[]SecurityRequirement `json:"security"`
+	Deprecated  bool                   `json:"deprecated"`
+}
+
+type Parameter struct {
+	Ref         string  `json:"$ref"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Ref         string               `json:"$ref"`
+	Description string               `json:"description"`
+	Required    bool                 `json:"required"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref         string             `json:"$ref"`
+	Type        string             `json:"type"`
+	Format      string             `json:"format"`
+	Description string             `json:"description"`
+	Properties  map[string]*Schema `json:"properties"`
+	Items       *Schema            `json:"items"`
+	Required    []string           `json:"required"`
+	Enum        []any              `json:"enum"`
+	AllOf       []*Schema          `json:"allOf"`
+}
+
+// Load reads an OpenAPI 3 document from a file path or an http(s) URL.
+// Only the JSON serialization is supported.
+func Load(ctx context.Context, source string) (*Document, error) {
+	data, err := read(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document %s (only JSON is supported): %w", source, err)
+	}
+
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q in %s", doc.OpenAPI, source)
+	}
+
+	return &doc, nil
+}
+
+func read(ctx context.Context, source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return os.ReadFile(source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch OpenAPI document: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func (d *Document) deref(s *Schema) *Schema {
+	for depth := 0; s != nil && s.Ref != "" && depth < 32; depth++ {
+		s = d.Components.Schemas[refName(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// resolveSchema follows $ref pointers and flattens allOf compositions into a
+// single object schema. A schema that includes itself through allOf is merged
+// only once.
+func (d *Document) resolveSchema(s *Schema) *Schema {
+	return d.flatten(s, make(map[*Schema]bool))
+}
+
+func (d *Document) flatten(s *Schema, visiting map[*Schema]bool) *Schema {
+	s = d.deref(s)
+	if s == nil || len(s.AllOf) == 0 {
+		return s
+	}
+	if visiting[s] {
+		return nil
+	}
+	visiting[s] = true
+	defer delete(visiting, s)
+
+	merged := &Schema{
+		Type:        "object",
+		Description: s.Description,
+		Properties:  make(map[string]*Schema),
+	}
+	merge := func(part *Schema) {
+		for name, prop := range part.Properties {
+			merged.Properties[name] = prop
+		}
+		merged.Required = append(merged.Required, part.Required...)
+	}
+
+	merge(s)
+	for _, part := range s.AllOf {
+		if resolved := d.flatten(part, visiting); resolved != nil {
+			merge(resolved)
+		}
+	}
+	return merged
+}
+
+func (d *Document) resolveParameter(p *Parameter) (*Parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	resolved, ok := d.Components.Parameters[refName(p.Ref, "#/components/parameters/")]
+	if !ok {
+		return nil, fmt.Errorf("unresolved parameter reference %s", p.Ref)
+	}
+	return resolved, nil
+}
+
+func (d *Document) resolveRequestBody(b *RequestBody) (*RequestBody, error) {
+	if b == nil || b.Ref == "" {
+		return b, nil
+	}
+	resolved, ok := d.Components.RequestBodies[refName(b.Ref, "#/components/requestBodies/")]
+	if !ok {
+		return nil, fmt.Errorf("unresolved request body reference %s", b.Ref)
+	}
+	return resolved, nil
+}
+
+func refName(ref, prefix string) string {
+	return strings.TrimPrefix(ref, prefix)
+}
@@ -0,0 +1,239 @@
+package promotions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownCode    = errors.New("unknown promotion code")
+	ErrNotEligible    = errors.New("cart is not eligible for promotion")
+	ErrAlreadyApplied = errors.New("promotion already applied")
+)
+
+type File struct {
+	Promotions []models.Promotion `json:"promotions"`
+}
+
+// Engine evaluates promotion rules locally for codes the backend does not
+// know about, and remembers which of them are applied to the session's cart.
+// The server acts for a single user, so one engine serves one cart; a server
+// handling several users needs an engine per session.
+type Engine struct {
+	mu       sync.Mutex
+	rules    map[string]models.Promotion
+	applied  []string
+	currency string
+	now      func() time.Time
+}
+
+func (e *Engine) List() []models.Promotion {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	list := make([]models.Promotion, 0, len(e.rules))
+	for _, rule := range e.rules {
+		list = append(list, rule)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+func (e *Engine) Has(code string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	_, ok := e.rules[normalize(code)]
+	return ok
+}
+
+func (e *Engine) IsApplied(code string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return slices.Contains(e.applied, normalize(code))
+}
+
+func (e *Engine) Applied() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return slices.Clone(e.applied)
+}
+
+// Apply checks the code against the cart and records it as applied.
+func (e *Engine) Apply(code string, cart *models.Cart) (models.Discount, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	code = normalize(code)
+	rule, ok := e.rules[code]
+	if !ok {
+		return models.Discount{}, fmt.Errorf("%w: %s", ErrUnknownCode, code)
+	}
+	if slices.Contains(e.applied, code) {
+		return models.Discount{}, fmt.Errorf("%w: %s", ErrAlreadyApplied, code)
+	}
+
+	amount, err := e.evaluate(rule, cart)
+	if err != nil {
+		return models.Discount{}, err
+	}
+
+	e.applied = append(e.applied, code)
+	return discount(rule, amount), nil
+}
+
+func (e *Engine) Remove(code string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	idx := slices.Index(e.applied, normalize(code))
+	if idx < 0 {
+		return false
+	}
+	e.applied = slices.Delete(e.applied, idx, idx+1)
+	return true
+}
+
+func (e *Engine) Clear() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.applied = nil
+}
+
+// Discounts recomputes every applied code against the current cart. Codes the
+// cart no longer qualifies for are skipped, and the combined discount never
+// exceeds the amount still payable.
+func (e *Engine) Discounts(cart *models.Cart) []models.Discount {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	remaining := cart.TotalIn(e.currency)
+	for _, d := range cart.Discounts {
+		remaining = remaining.Sub(d.AmountIn(e.currency))
+	}
+
+	discounts := make([]models.Discount, 0, len(e.applied))
+	for _, code := range e.applied {
+		rule := e.rules[code]
+		amount, err := e.evaluate(rule, cart)
+		if err != nil {
+			continue
+		}
+		amount = money.Min(amount, money.Max(remaining, money.Zero(e.currency)))
+		remaining = remaining.Sub(amount)
+		discounts = append(discounts, discount(rule, amount))
+	}
+	return discounts
+}
+
+// evaluate returns the discount rule gives on cart, in the store currency.
+func (e *Engine) evaluate(rule models.Promotion, cart *models.Cart) (money.Money, error) {
+	if rule.ExpiresAt != nil && e.now().After(*rule.ExpiresAt) {
+		return money.Money{}, fmt.Errorf("%w: %s expired on %s", ErrNotEligible, rule.Code, rule.ExpiresAt.Format(time.DateOnly))
+	}
+
+	eligible := money.Zero(e.currency)
+	for _, item := range cart.CartItems {
+		if rule.CategoryId == 0 || item.Product.CategoryId == rule.CategoryId {
+			eligible = eligible.Add(item.SubtotalIn(e.currency))
+		}
+	}
+
+	if eligible.IsZero() {
+		if rule.CategoryId != 0 {
+			return money.Money{}, fmt.Errorf("%w: %s only applies to products in category %d", ErrNotEligible, rule.Code, rule.CategoryId)
+		}
+		return money.Money{}, fmt.Errorf("%w: the cart is empty", ErrNotEligible)
+	}
+	if minSubtotal := money.FromFloat(rule.MinSubtotal, e.currency); eligible.Cmp(minSubtotal) < 0 {
+		return money.Money{}, fmt.Errorf("%w: %s requires a subtotal of at least %s, eligible items total %s", ErrNotEligible, rule.Code, minSubtotal, eligible)
+	}
+
+	switch rule.Type {
+	case models.PromotionPercentage:
+		return eligible.Percent(rule.Value), nil
+	case models.PromotionFixedAmount:
+		return money.Min(money.FromFloat(rule.Value, e.currency), eligible), nil
+	}
+	return money.Zero(e.currency), nil
+}
+
+// discount reports amount as a cart discount; models carry decimal amounts
+// like the backends do.
+func discount(rule models.Promotion, amount money.Money) models.Discount {
+	return models.Discount{
+		Code:        rule.Code,
+		Description: rule.Description,
+		Amount:      amount.Float(),
+	}
+}
+
+func normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func validate(rule models.Promotion) error {
+	switch {
+	case rule.Code == "":
+		return errors.New("promotion without code")
+	case rule.Type == models.PromotionPercentage && (rule.Value <= 0 || rule.Value > 100):
+		return fmt.Errorf("promotion %s: percentage must be between 0 and 100", rule.Code)
+	case rule.Type == models.PromotionFixedAmount && rule.Value <= 0:
+		return fmt.Errorf("promotion %s: amount must be positive", rule.Code)
+	case rule.Type != models.PromotionPercentage && rule.Type != models.PromotionFixedAmount:
+		return fmt.Errorf("promotion %s: unknown type %q", rule.Code, rule.Type)
+	}
+	return nil
+}
+
+// Load reads promotion rules from a JSON file. Amounts are in the store
+// currency. An empty path yields an engine without rules.
+func Load(path, currency string) (*Engine, error) {
+	if path == "" {
+		return NewEngine(nil, currency)
+	}
+
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file File
+	if err := json.Unmarshal(bs, &file); err != nil {
+		return nil, fmt.Errorf("invalid promotions file %s: %w", path, err)
+	}
+
+	return NewEngine(file.Promotions, currency)
+}
+
+func NewEngine(rules []models.Promotion, currency string) (*Engine, error) {
+	e := &Engine{
+		rules:    make(map[string]models.Promotion, len(rules)),
+		currency: currency,
+		now:      time.Now,
+	}
+
+	for _, rule := range rules {
+		if err := validate(rule); err != nil {
+			return nil, err
+		}
+		rule.Code = normalize(rule.Code)
+		if _, dup := e.rules[rule.Code]; dup {
+			return nil, fmt.Errorf("duplicate promotion code %s", rule.Code)
+		}
+		e.rules[rule.Code] = rule
+	}
+	return e, nil
+}
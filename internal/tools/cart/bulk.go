@@ -0,0 +1,288 @@
+package cart
+
+import (
+	"context"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"strings"
+	"sync"
+)
+
+const maxBulkLines = 50
+
+type bulkLine struct {
+	index    int
+	ref      string
+	product  *models.Product
+	quantity int
+	err      error
+}
+
+func (c *CartToolset) registerBulkTools() {
+	c.reg.Register(mcp.Tool{
+		Name:        "add_items_to_cart",
+		Title:       "Add Items to Cart",
+		Description: "Add several products to the shopping cart in one call. Every line is checked against the catalog before anything is added (requires authentication)",
+		InputSchema: mcp.InputSchema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"items": {
+					Type:        "array",
+					Description: fmt.Sprintf("Lines to add (at most %d), each naming a product by product_id or sku", maxBulkLines),
+					Items: &mcp.Items{
+						Type: "object",
+						Properties: map[string]mcp.Property{
+							"product_id": {
+								Type:        "number",
+								Description: "ID of the product to add",
+							},
+							"sku": {
+								Type:        "string",
+								Description: "SKU of the product to add, if product_id is not given",
+							},
+							"quantity": {
+								Type:        "number",
+								Description: "Quantity to add (default: 1)",
+							},
+						},
+					},
+				},
+			},
+			Required: []string{"items"},
+		},
+		Annotations: mcp.Mutating(false, false),
+	}, c.snapshots.Track(c.backend, "add_items_to_cart", c.handleAddItemsToCart))
+}
+
+func (c *CartToolset) handleAddItemsToCart(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	c.logger.InfoContext(ctx, "Adding items to cart", "args", args)
+
+	raw, ok := args["items"].([]any)
+	if !ok || len(raw) == 0 {
+		return mcp.NewToolCallError("items must be a non-empty array of {product_id or sku, quantity}"), nil
+	}
+	if len(raw) > maxBulkLines {
+		return mcp.NewToolCallError(fmt.Sprintf("At most %d items can be added in one call; split the request", maxBulkLines)), nil
+	}
+
+	before, err := c.backend.GetCart(ctx)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch cart: %w", err)
+	}
+
+	lines := c.validateLines(ctx, raw, before)
+	invalid := 0
+	for _, line := range lines {
+		if line.err != nil {
+			invalid++
+		}
+	}
+	if invalid > 0 {
+		return mcp.NewToolCallError(fmt.Sprintf("Nothing was added: %d of %d lines failed validation.\n\n%s",
+			invalid, len(lines), strings.TrimSuffix(formatLines(lines, "valid"), "\n"))), nil
+	}
+
+	c.addLines(ctx, lines)
+
+	failed := 0
+	for _, line := range lines {
+		if line.err != nil {
+			failed++
+		}
+	}
+
+	summary := fmt.Sprintf("✓ Added all %d lines to cart", len(lines))
+	if failed > 0 {
+		summary = fmt.Sprintf("⚠ Added %d of %d lines to cart", len(lines)-failed, len(lines))
+		if c.opts.BulkRollback {
+			summary = fmt.Sprintf("✗ %d of %d lines failed; %s", failed, len(lines), c.rollback(ctx, lines, before))
+		}
+	}
+
+	after, err := c.backend.GetCart(ctx)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch cart: %w", err)
+	}
+
+	c.logger.InfoContext(ctx, "Added items to cart", "lines", len(lines), "failed", failed)
+
+	return mcp.CallToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: fmt.Sprintf("%s\n\n%s\n%s", summary, formatLines(lines, "added"), c.formatCart(after)),
+			},
+		},
+		IsError: failed > 0,
+	}, nil
+}
+
+// validateLines resolves every line against the catalog and checks the
+// requested quantity, including what is already in the cart, against stock.
+func (c *CartToolset) validateLines(ctx context.Context, raw []any, cart *models.Cart) []*bulkLine {
+	lines := make([]*bulkLine, len(raw))
+	seen := make(map[int]int)
+
+	for i, entry := range raw {
+		line := &bulkLine{index: i + 1, quantity: 1}
+		lines[i] = line
+
+		fields, ok := entry.(map[string]any)
+		if !ok {
+			line.err = fmt.Errorf("line must be an object")
+			continue
+		}
+
+		if q, ok := fields["quantity"]; ok {
+			quantity, ok := q.(float64)
+			if !ok || quantity < 1 || quantity != float64(int(quantity)) {
+				line.err = fmt.Errorf("quantity must be a whole number of at least 1")
+				continue
+			}
+			line.quantity = int(quantity)
+		}
+
+		var err error
+		switch {
+		case fields["product_id"] != nil:
+			id, ok := fields["product_id"].(float64)
+			if !ok {
+				line.err = fmt.Errorf("invalid product_id %v", fields["product_id"])
+				continue
+			}
+			line.ref = fmt.Sprintf("product %d", int(id))
+			line.product, err = c.backend.GetProduct(ctx, int(id))
+		case fields["sku"] != nil:
+			sku, _ := fields["sku"].(string)
+			if strings.TrimSpace(sku) == "" {
+				line.err = fmt.Errorf("invalid sku %v", fields["sku"])
+				continue
+			}
+			line.ref = "SKU " + sku
+			line.product, err = c.backend.GetProductBySKU(ctx, sku)
+		default:
+			line.err = fmt.Errorf("product_id or sku is required")
+			continue
+		}
+		if err != nil {
+			line.err = err
+			continue
+		}
+
+		p := line.product
+		if first, dup := seen[p.Id]; dup {
+			line.err = fmt.Errorf("%s is already listed on line %d; combine the quantities", p.Name, first)
+			continue
+		}
+		seen[p.Id] = line.index
+
+		if !p.IsActive {
+			line.err = fmt.Errorf("%s is not available", p.Name)
+			continue
+		}
+
+		inCart := 0
+		if item := cartLine(cart, p.Id); item != nil {
+			inCart = item.Quantity
+		}
+		if inCart+line.quantity > p.Stock {
+			line.err = fmt.Errorf("only %d of %s in stock (%d already in cart)", p.Stock, p.Name, inCart)
+		}
+	}
+
+	return lines
+}
+
+func (c *CartToolset) addLines(ctx context.Context, lines []*bulkLine) {
+	concurrency := max(c.opts.BulkConcurrency, 1)
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for _, line := range lines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				line.err = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			if _, err := c.backend.AddToCart(ctx, line.product.Id, line.quantity); err != nil {
+				line.err = err
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// rollback restores the quantity each successfully added product had before
+// the call, removing lines that did not exist then.
+func (c *CartToolset) rollback(ctx context.Context, lines []*bulkLine, before *models.Cart) string {
+	current, err := c.backend.GetCart(ctx)
+	if err != nil {
+		return fmt.Sprintf("rollback failed, could not fetch cart: %s", err)
+	}
+
+	var failures []string
+	for _, line := range lines {
+		if line.err != nil {
+			continue
+		}
+
+		item := cartLine(current, line.product.Id)
+		if item == nil {
+			continue
+		}
+
+		if previous := cartLine(before, line.product.Id); previous != nil {
+			_, err = c.backend.UpdateCartItem(ctx, item.Id, previous.Quantity)
+		} else {
+			_, err = c.backend.RemoveCartItem(ctx, item.Id)
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("line %d (%s): %s", line.index, line.product.Name, err))
+			continue
+		}
+		line.err = fmt.Errorf("rolled back")
+	}
+
+	if len(failures) > 0 {
+		c.logger.WarnContext(ctx, "Bulk add rollback incomplete", "failures", failures)
+		return "rollback incomplete: " + strings.Join(failures, "; ")
+	}
+	return "the lines that were added have been rolled back"
+}
+
+func cartLine(cart *models.Cart, productID int) *models.CartItem {
+	for i := range cart.CartItems {
+		if cart.CartItems[i].Product.Id == productID {
+			return &cart.CartItems[i]
+		}
+	}
+	return nil
+}
+
+func formatLines(lines []*bulkLine, status string) string {
+	var b strings.Builder
+	for _, line := range lines {
+		name := line.ref
+		if line.product != nil {
+			name = fmt.Sprintf("%s (ID: %d)", line.product.Name, line.product.Id)
+		}
+		if name == "" {
+			name = "?"
+		}
+
+		if line.err != nil {
+			fmt.Fprintf(&b, "✗ Line %d: %s × %d - %s\n", line.index, name, line.quantity, line.err)
+		} else {
+			fmt.Fprintf(&b, "✓ Line %d: %s × %d - %s\n", line.index, name, line.quantity, status)
+		}
+	}
+	return b.String()
+}
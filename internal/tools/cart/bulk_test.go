@@ -0,0 +1,40 @@
+package cart
+
+import (
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/tooltest"
+	"testing"
+)
+
+func TestAddItemsToCart(t *testing.T) {
+	reg, _ := newToolset(t, "add_items_to_cart", Options{BulkConcurrency: 1})
+
+	result := tooltest.Call(t, reg, "add_items_to_cart", `{"items": [
+		{"product_id": 4},
+		{"sku": "ACC-FILT-02", "quantity": 2}
+	]}`)
+	tooltest.Contains(t, result,
+		"Added all 2 lines to cart",
+		"Pour-Over Dripper (ID: 4) × 1 - added",
+		"Paper Filters Size 02 (100 pack) (ID: 7) × 2 - added",
+		"Total: $40.00")
+}
+
+func TestAddItemsToCartValidatesEveryLine(t *testing.T) {
+	reg, _ := newToolset(t, "add_items_to_cart_invalid", Options{BulkConcurrency: 1})
+
+	result := tooltest.Call(t, reg, "add_items_to_cart", `{"items": [
+		{"product_id": 4},
+		{"product_id": 6, "quantity": 5},
+		{"product_id": 9}
+	]}`)
+	if !result.IsError {
+		t.Fatalf("expected a tool error, got %q", tooltest.Text(result))
+	}
+	tooltest.Contains(t, result,
+		"Nothing was added: 2 of 3 lines failed validation",
+		"only 3 of Burr Grinder in stock",
+		"Travel Mug is not available")
+
+	result = tooltest.Call(t, reg, "view_cart", "")
+	tooltest.Contains(t, result, "Your cart is empty")
+}
@@ -2,23 +2,53 @@ package cart
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/client"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/checkout"
 	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/promotions"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/products"
 	"log/slog"
 )
 
+type Options struct {
+	// BulkConcurrency bounds how many add_items_to_cart lines are added at once.
+	BulkConcurrency int
+	// BulkRollback undoes the lines add_items_to_cart already added when
+	// another line fails.
+	BulkRollback bool
+	// Promotions evaluates promotion codes the backend does not support.
+	Promotions *promotions.Engine
+	// Snapshots records the cart before every change so it can be undone.
+	Snapshots *Snapshots
+	// Checkout holds the shipping and tax rules for estimate_checkout_total,
+	// which is not registered without them.
+	Checkout *checkout.Engine
+	// Money formats amounts in the session or requested currency.
+	Money *money.Display
+}
+
 type CartToolset struct {
 	reg        *mcp.Registry
 	logger     *slog.Logger
-	restClient *client.RestClient
+	backend    backend.Backend
+	resolver   *products.Resolver
+	validator  *Validator
+	promotions *promotions.Engine
+	snapshots  *Snapshots
+	checkout   *checkout.Engine
+	money      *money.Display
+	opts       Options
 }
 
 func (c *CartToolset) registerCartTools() {
 	c.reg.Register(mcp.Tool{
 		Name:        "add_to_cart",
-		Description: "Add a product to the shopping cart (requires authentication)",
+		Title:       "Add to Cart",
+		Description: "Add a product to the shopping cart by product_id, sku or name (requires authentication)",
 		InputSchema: mcp.InputSchema{
 			Type: "object",
 			Properties: map[string]mcp.Property{
@@ -26,137 +56,382 @@ func (c *CartToolset) registerCartTools() {
 					Type:        "number",
 					Description: "ID of the product to add",
 				},
+				"sku": {
+					Type:        "string",
+					Description: "SKU of the product to add, if product_id is not known",
+				},
+				"name": {
+					Type:        "string",
+					Description: "Name of the product to add, if neither product_id nor sku is known. If several products match, the candidates are returned instead",
+				},
 				"quantity": {
 					Type:        "number",
 					Description: "Quantity to add (default: 1)",
 				},
 			},
-			Required: []string{"product_id"},
+			Required: []string{},
 		},
-	}, c.handleAddToCart)
+		Annotations: mcp.Mutating(false, false),
+	}, c.snapshots.Track(c.backend, "add_to_cart", c.handleAddToCart))
 
 	c.reg.Register(mcp.Tool{
 		Name:        "view_cart",
+		Title:       "View Cart",
 		Description: "View current shopping cart contents (requires authentication)",
+		InputSchema: mcp.InputSchema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"currency": {
+					Type:        "string",
+					Description: "Currency to show amounts in, e.g. EUR or GBP (default: the session currency set with set_currency)",
+				},
+			},
+			Required: []string{},
+		},
+		Annotations: mcp.ReadOnly(),
+	}, c.handleViewCart)
+
+	c.reg.Register(mcp.Tool{
+		Name:        "update_cart_item",
+		Title:       "Update Cart Item",
+		Description: "Change the quantity of a cart line, identified by item_id or product_id as shown by view_cart (requires authentication)",
+		InputSchema: mcp.InputSchema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"item_id": {
+					Type:        "number",
+					Description: "ID of the cart item to update",
+				},
+				"product_id": {
+					Type:        "number",
+					Description: "ID of the product whose cart line to update, if item_id is not given",
+				},
+				"quantity": {
+					Type:        "number",
+					Description: "New quantity for the line (at least 1; use remove_from_cart to remove it)",
+				},
+			},
+			Required: []string{"quantity"},
+		},
+		Annotations: mcp.Mutating(true, true),
+	}, c.snapshots.Track(c.backend, "update_cart_item", c.handleUpdateCartItem))
+
+	c.reg.Register(mcp.Tool{
+		Name:        "remove_from_cart",
+		Title:       "Remove from Cart",
+		Description: "Remove a line from the shopping cart, identified by item_id or product_id as shown by view_cart (requires authentication)",
+		InputSchema: mcp.InputSchema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"item_id": {
+					Type:        "number",
+					Description: "ID of the cart item to remove",
+				},
+				"product_id": {
+					Type:        "number",
+					Description: "ID of the product whose cart line to remove, if item_id is not given",
+				},
+			},
+			Required: []string{},
+		},
+		Annotations: mcp.Mutating(true, true),
+	}, c.snapshots.Track(c.backend, "remove_from_cart", c.handleRemoveFromCart))
+
+	c.reg.Register(mcp.Tool{
+		Name:        "clear_cart",
+		Title:       "Clear Cart",
+		Description: "Remove every item from the shopping cart (requires authentication)",
 		InputSchema: mcp.InputSchema{
 			Type:       "object",
 			Properties: map[string]mcp.Property{},
 			Required:   []string{},
 		},
-	}, c.handleViewCart)
+		Annotations: mcp.Mutating(true, true),
+	}, c.snapshots.Track(c.backend, "clear_cart", c.handleClearCart))
 }
 
-func (c *CartToolset) handleAddToCart(_ context.Context, args map[string]any) (mcp.CallToolResult, error) {
-	c.logger.Info("Adding to cart", "args", args)
-
-	productIDFloat, ok := args["product_id"].(float64)
-	if !ok {
-		return mcp.CallToolResult{}, fmt.Errorf("invalid product_id; %+v", args["product_id"])
-	}
+func (c *CartToolset) handleAddToCart(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	c.logger.InfoContext(ctx, "Adding to cart", "args", args)
 
-	productID := uint(productIDFloat)
-	var quantity uint = 1
-	if q, ok := args["quantity"].(uint); ok {
-		if q == 0 {
-			q = 1
+	ref := products.Ref{}
+	ref.SKU, _ = args["sku"].(string)
+	ref.Name, _ = args["name"].(string)
+	if v, ok := args["product_id"]; ok {
+		productIDFloat, ok := v.(float64)
+		if !ok {
+			return mcp.CallToolResult{}, fmt.Errorf("invalid product_id; %+v", v)
 		}
-		quantity = q
+		ref.ProductID = int(productIDFloat)
 	}
 
-	body := map[string]interface{}{
-		"product_id": productID,
-		"quantity":   quantity,
+	quantity := 1
+	if q, ok := args["quantity"].(float64); ok && q >= 1 {
+		quantity = int(q)
 	}
 
-	response, err := c.restClient.WithToken().Post("/cart/items", body)
+	product, err := c.resolver.Resolve(ctx, ref)
+	var ambiguous *products.AmbiguousError
+	if errors.As(err, &ambiguous) {
+		return mcp.NewToolCallError(ambiguous.Prompt(c.formatter())), nil
+	}
+	if errors.Is(err, backend.ErrInvalid) {
+		return mcp.NewToolCallError("product_id, sku or name is required"), nil
+	}
 	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to find %s: %w", ref, err)
+	}
+
+	productID := product.Id
+	if _, err := c.backend.AddToCart(ctx, productID, quantity); err != nil {
 		return mcp.CallToolResult{}, fmt.Errorf("failed to add to cart: %w", err)
 	}
 
-	c.logger.Info("Added to cart", "response", string(response))
+	c.logger.InfoContext(ctx, "Added to cart", "product_id", productID, "quantity", quantity)
+
+	return mcp.CallToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: fmt.Sprintf("✓ Successfully added %s (product %d, quantity: %d) to cart", product.Name, productID, quantity),
+			},
+		},
+	}, nil
+}
+
+func (c *CartToolset) handleViewCart(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+
+	c.logger.InfoContext(ctx, "Viewing cart", "args", args)
 
-	var cartItem AddToCartResponse
-	if err := json.Unmarshal(response, &cartItem); err != nil {
-		return mcp.CallToolResult{}, fmt.Errorf("failed to parse response: %w", err)
+	currency, _ := args["currency"].(string)
+	format, err := c.money.Formatter(currency)
+	if err != nil {
+		return mcp.NewToolCallError(err.Error()), nil
 	}
 
-	if cartItem.Error != "" {
-		return mcp.CallToolResult{
-			Content: []mcp.Content{
-				{
-					Type: "text",
-					Text: fmt.Sprintf("Error: %s", cartItem.Error),
-				},
-			},
-			IsError: true,
-		}, nil
+	cart, err := c.backend.GetCart(ctx)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch cart: %w", err)
 	}
 
+	c.logger.InfoContext(ctx, "Fetched cart", "items", len(cart.CartItems))
+
 	return mcp.CallToolResult{
 		Content: []mcp.Content{
 			{
 				Type: "text",
-				Text: fmt.Sprintf("✓ Successfully added product %d (quantity: %d) to cart", productID, quantity),
+				Text: renderCart(cart, c.promotions.Discounts(cart), format) + format.Note(),
 			},
 		},
 	}, nil
+
 }
 
-func (c *CartToolset) handleViewCart(_ context.Context, args map[string]any) (mcp.CallToolResult, error) {
+func (c *CartToolset) handleUpdateCartItem(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	c.logger.InfoContext(ctx, "Updating cart item", "args", args)
 
-	c.logger.Info("Viewing cart", "args", args)
+	quantity, ok := args["quantity"].(float64)
+	if !ok || quantity < 1 || quantity != float64(int(quantity)) {
+		return mcp.NewToolCallError("quantity must be a whole number of at least 1; use remove_from_cart to remove the item"), nil
+	}
 
-	response, err := c.restClient.WithToken().Get("/cart", nil)
+	cart, err := c.backend.GetCart(ctx)
 	if err != nil {
 		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch cart: %w", err)
 	}
 
-	c.logger.Info("Fetched cart", "response", string(response))
+	item, msg := findCartItem(cart, args)
+	if item == nil {
+		return mcp.NewToolCallError(msg), nil
+	}
 
-	var cart ViewCartResponse
-	if err := json.Unmarshal(response, &cart); err != nil {
-		return mcp.CallToolResult{}, fmt.Errorf("failed to parse cart: %w", err)
+	product, err := c.backend.GetProduct(ctx, item.Product.Id)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch product details: %w", err)
+	}
+	if !product.IsActive {
+		return mcp.NewToolCallError(fmt.Sprintf("%s is no longer available; remove it with remove_from_cart", product.Name)), nil
+	}
+	if int(quantity) > product.Stock {
+		return mcp.NewToolCallError(fmt.Sprintf("Only %d of %s in stock; choose a quantity of %d or less", product.Stock, product.Name, product.Stock)), nil
 	}
 
-	if len(cart.Data.CartItems) == 0 {
-		return mcp.CallToolResult{
-			Content: []mcp.Content{
-				{
-					Type: "text",
-					Text: "🛒 Your cart is empty",
-				},
+	updated, err := c.backend.UpdateCartItem(ctx, item.Id, int(quantity))
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to update cart item: %w", err)
+	}
+
+	c.logger.InfoContext(ctx, "Updated cart item", "item_id", item.Id, "product_id", item.Product.Id, "quantity", int(quantity))
+
+	return mcp.CallToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: fmt.Sprintf("✓ Updated %s to quantity %d\n\n%s", item.Product.Name, int(quantity), c.formatCart(updated)),
 			},
-		}, nil
+		},
+	}, nil
+}
+
+func (c *CartToolset) handleRemoveFromCart(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	c.logger.InfoContext(ctx, "Removing from cart", "args", args)
+
+	cart, err := c.backend.GetCart(ctx)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch cart: %w", err)
 	}
 
-	resultText := fmt.Sprintf("🛒 Shopping Cart (%d items):\n\n", len(cart.Data.CartItems))
-	for i, item := range cart.Data.CartItems {
-		resultText += fmt.Sprintf("%d. %s - $%.2f × %d = $%.2f\n", i+1,
-			item.Product.Name,
-			item.Product.Price,
-			item.Quantity,
-			item.Subtotal)
+	item, msg := findCartItem(cart, args)
+	if item == nil {
+		return mcp.NewToolCallError(msg), nil
 	}
 
-	resultText += fmt.Sprintf("\n💰 Total: $%.2f", cart.Data.Total)
+	updated, err := c.backend.RemoveCartItem(ctx, item.Id)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to remove cart item: %w", err)
+	}
+
+	c.logger.InfoContext(ctx, "Removed from cart", "item_id", item.Id, "product_id", item.Product.Id)
 
 	return mcp.CallToolResult{
 		Content: []mcp.Content{
 			{
 				Type: "text",
-				Text: resultText,
+				Text: fmt.Sprintf("✓ Removed %s from cart\n\n%s", item.Product.Name, c.formatCart(updated)),
 			},
 		},
 	}, nil
+}
 
+func (c *CartToolset) handleClearCart(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	c.logger.InfoContext(ctx, "Clearing cart", "args", args)
+
+	updated, err := c.backend.ClearCart(ctx)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to clear cart: %w", err)
+	}
+
+	c.logger.InfoContext(ctx, "Cleared cart")
+
+	return mcp.CallToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: fmt.Sprintf("✓ Cart cleared\n\n%s", c.formatCart(updated)),
+			},
+		},
+	}, nil
 }
 
-func NewCartToolset(reg *mcp.Registry, restClient *client.RestClient, logger *slog.Logger) *CartToolset {
+// findCartItem locates the cart line named by item_id or product_id. When no
+// line matches it returns nil and a message for the caller.
+func findCartItem(cart *models.Cart, args map[string]any) (*models.CartItem, string) {
+	itemID, byItem := args["item_id"].(float64)
+	productID, byProduct := args["product_id"].(float64)
+	if !byItem && !byProduct {
+		return nil, "item_id or product_id is required; call view_cart to see the cart lines"
+	}
+
+	for i := range cart.CartItems {
+		item := &cart.CartItems[i]
+		if byItem && item.Id == int(itemID) || !byItem && item.Product.Id == int(productID) {
+			return item, ""
+		}
+	}
+
+	if byItem {
+		return nil, fmt.Sprintf("Cart item %d is not in the cart; call view_cart to see the cart lines", int(itemID))
+	}
+	return nil, fmt.Sprintf("Product %d is not in the cart; call view_cart to see the cart lines", int(productID))
+}
+
+// formatCart renders the cart in the session currency together with the
+// discounts of locally applied promotion codes.
+func (c *CartToolset) formatCart(cart *models.Cart) string {
+	format := c.formatter()
+	return renderCart(cart, c.promotions.Discounts(cart), format) + format.Note()
+}
+
+// formatter formats in the session currency, which Display has already
+// validated.
+func (c *CartToolset) formatter() money.Formatter {
+	format, _ := c.money.Formatter("")
+	return format
+}
+
+func renderCart(cart *models.Cart, local []models.Discount, format money.Formatter) string {
+	if len(cart.CartItems) == 0 {
+		return "🛒 Your cart is empty"
+	}
+
+	resultText := fmt.Sprintf("🛒 Shopping Cart (%d items):\n\n", len(cart.CartItems))
+	for i, item := range cart.CartItems {
+		resultText += fmt.Sprintf("%d. %s (item %d, product %d) - %s × %d = %s\n", i+1,
+			item.Product.Name,
+			item.Id,
+			item.Product.Id,
+			format.Format(item.Product.Price),
+			item.Quantity,
+			format.Format(item.Subtotal))
+	}
+
+	if len(cart.Discounts) == 0 && len(local) == 0 {
+		resultText += fmt.Sprintf("\n💰 Total: %s", format.Format(cart.Total))
+		return resultText
+	}
+
+	total := format.Store(cart.Total)
+	resultText += fmt.Sprintf("\nSubtotal: %s\n", format.Format(cart.Total))
+	for _, d := range cart.Discounts {
+		resultText += fmt.Sprintf("🏷 %s - %s: -%s\n", d.Code, d.Description, format.Format(d.Amount))
+		total = total.Sub(format.Store(d.Amount))
+	}
+	for _, d := range local {
+		resultText += fmt.Sprintf("🏷 %s - %s: -%s (local promotion, not yet confirmed by the store)\n", d.Code, d.Description, format.Format(d.Amount))
+		total = total.Sub(format.Store(d.Amount))
+	}
+	if total.IsNegative() {
+		total = format.Store(0)
+	}
+
+	resultText += fmt.Sprintf("\n💰 Total: %s", format.FormatMoney(total))
+	return resultText
+}
+
+func NewCartToolset(reg *mcp.Registry, store backend.Backend, opts Options, logger *slog.Logger) *CartToolset {
+	engine := opts.Promotions
+	if engine == nil {
+		engine, _ = promotions.NewEngine(nil, money.USD)
+	}
+	snapshots := opts.Snapshots
+	if snapshots == nil {
+		snapshots = NewSnapshots(DefaultSnapshotLimit, engine, logger)
+	}
+	rules := opts.Checkout
+	if rules == nil {
+		rules, _ = checkout.NewEngine(checkout.Rules{}, money.USD)
+	}
+	display := opts.Money
+	if display == nil {
+		display, _ = money.NewDisplay(money.USD, nil)
+	}
+
 	ct := &CartToolset{
 		reg:        reg,
-		restClient: restClient,
+		backend:    store,
+		resolver:   products.NewResolver(store),
+		validator:  NewValidator(store, display.Store()),
+		promotions: engine,
+		snapshots:  snapshots,
+		checkout:   rules,
+		money:      display,
+		opts:       opts,
 		logger:     logger,
 	}
 	ct.registerCartTools()
+	ct.registerBulkTools()
+	ct.registerValidateTools()
+	ct.registerCouponTools()
+	ct.registerSnapshotTools()
+	ct.registerEstimateTools()
 	return ct
 }
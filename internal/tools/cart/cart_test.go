@@ -0,0 +1,85 @@
+package cart
+
+import (
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/tooltest"
+	"testing"
+)
+
+// newToolset registers the cart tools against the cassette's backend, starting
+// from an empty cart.
+func newToolset(t *testing.T, cassette string, opts Options) (*mcp.Registry, backend.Backend) {
+	t.Helper()
+
+	reg, store := tooltest.Setup(t, cassette, tooltest.Options{})
+	NewCartToolset(reg, store, opts, tooltest.Logger())
+	return reg, store
+}
+
+func TestAddToCart(t *testing.T) {
+	reg, _ := newToolset(t, "add_to_cart", Options{})
+
+	result := tooltest.Call(t, reg, "add_to_cart", `{"product_id": 1, "quantity": 2}`)
+	tooltest.Contains(t, result, "Successfully added Ethiopia Yirgacheffe Whole Beans 1kg (product 1, quantity: 2)")
+
+	result = tooltest.Call(t, reg, "add_to_cart", `{"sku": "brw-drip-02"}`)
+	tooltest.Contains(t, result, "Successfully added Pour-Over Dripper (product 4, quantity: 1)")
+
+	result = tooltest.Call(t, reg, "add_to_cart", `{"name": "whole beans"}`)
+	if !result.IsError {
+		t.Fatalf("expected candidates for an ambiguous name, got %q", tooltest.Text(result))
+	}
+	tooltest.Contains(t, result, "matches several products", "COF-ETH-1KG", "COF-ESP-1KG")
+
+	result = tooltest.Call(t, reg, "view_cart", "")
+	tooltest.Contains(t, result,
+		"Shopping Cart (2 items)",
+		"product 1) - $24.50 × 2 = $49.00",
+		"product 4) - $29.00 × 1 = $29.00",
+		"Total: $78.00")
+}
+
+func TestAddToCartOverStock(t *testing.T) {
+	reg, _ := newToolset(t, "add_to_cart_over_stock", Options{})
+
+	if _, err := tooltest.Try(t, reg, "add_to_cart", `{"product_id": 6, "quantity": 4}`); err == nil {
+		t.Error("expected an error when adding more than the stock")
+	}
+
+	result := tooltest.Call(t, reg, "list_cart_snapshots", "")
+	tooltest.Contains(t, result, "No cart snapshots yet")
+}
+
+func TestUpdateCartItem(t *testing.T) {
+	reg, _ := newToolset(t, "update_cart_item", Options{})
+
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 2}`)
+
+	result := tooltest.Call(t, reg, "update_cart_item", `{"product_id": 2, "quantity": 3}`)
+	tooltest.Contains(t, result, "Updated Colombia Supremo Ground 500g to quantity 3", "$12.90 × 3 = $38.70")
+
+	result = tooltest.Call(t, reg, "update_cart_item", `{"product_id": 6, "quantity": 1}`)
+	if !result.IsError {
+		t.Errorf("expected a tool error for a product not in the cart, got %q", tooltest.Text(result))
+	}
+
+	result = tooltest.Call(t, reg, "update_cart_item", `{"product_id": 2, "quantity": 100}`)
+	if !result.IsError {
+		t.Fatalf("expected a tool error above stock, got %q", tooltest.Text(result))
+	}
+	tooltest.Contains(t, result, "Only 65 of Colombia Supremo Ground 500g in stock")
+}
+
+func TestRemoveFromCartAndClearCart(t *testing.T) {
+	reg, _ := newToolset(t, "remove_and_clear", Options{})
+
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 7}`)
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 8}`)
+
+	result := tooltest.Call(t, reg, "remove_from_cart", `{"product_id": 7}`)
+	tooltest.Contains(t, result, "Removed Paper Filters Size 02 (100 pack) from cart", "Shopping Cart (1 items)", "Stoneware Mug")
+
+	result = tooltest.Call(t, reg, "clear_cart", "")
+	tooltest.Contains(t, result, "Cart cleared", "Your cart is empty")
+}
@@ -0,0 +1,225 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/promotions"
+	"strings"
+	"time"
+)
+
+func (c *CartToolset) registerCouponTools() {
+	c.reg.Register(mcp.Tool{
+		Name:        "apply_coupon",
+		Title:       "Apply Coupon",
+		Description: "Apply a coupon or promotion code to the shopping cart and show the discounted total (requires authentication)",
+		InputSchema: mcp.InputSchema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"code": {
+					Type:        "string",
+					Description: "Coupon or promotion code, as given by the user or list_promotions",
+				},
+			},
+			Required: []string{"code"},
+		},
+		Annotations: mcp.Mutating(false, true),
+	}, c.snapshots.Track(c.backend, "apply_coupon", c.handleApplyCoupon))
+
+	c.reg.Register(mcp.Tool{
+		Name:        "remove_coupon",
+		Title:       "Remove Coupon",
+		Description: "Remove an applied coupon or promotion code from the shopping cart (requires authentication)",
+		InputSchema: mcp.InputSchema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"code": {
+					Type:        "string",
+					Description: "Code to remove",
+				},
+			},
+			Required: []string{"code"},
+		},
+		Annotations: mcp.Mutating(true, true),
+	}, c.snapshots.Track(c.backend, "remove_coupon", c.handleRemoveCoupon))
+
+	c.reg.Register(mcp.Tool{
+		Name:        "list_promotions",
+		Title:       "List Promotions",
+		Description: "List the promotion codes currently available and which of them are applied to the cart",
+		InputSchema: mcp.InputSchema{
+			Type:       "object",
+			Properties: map[string]mcp.Property{},
+			Required:   []string{},
+		},
+		Annotations: mcp.ReadOnly(),
+	}, c.handleListPromotions)
+}
+
+func (c *CartToolset) handleApplyCoupon(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	c.logger.InfoContext(ctx, "Applying coupon", "args", args)
+
+	code, _ := args["code"].(string)
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return mcp.NewToolCallError("code is required"), nil
+	}
+	if c.promotions.IsApplied(code) {
+		return mcp.NewToolCallError(fmt.Sprintf("%s is already applied to the cart", code)), nil
+	}
+
+	cart, err := c.backend.ApplyCoupon(ctx, code)
+	switch {
+	case err == nil:
+		c.logger.InfoContext(ctx, "Applied coupon", "code", code, "source", "backend")
+		return couponResult(fmt.Sprintf("✓ Applied coupon %s", code), c.formatCart(cart)), nil
+	case unsupportedOrUnknown(err) && c.promotions.Has(code):
+		return c.applyLocal(ctx, code)
+	case errors.Is(err, backend.ErrUnsupported):
+		return mcp.NewToolCallError(fmt.Sprintf("The store does not support coupons and %s is not a configured promotion. Call list_promotions to see the available codes.", code)), nil
+	case errors.Is(err, backend.ErrNotFound), errors.Is(err, backend.ErrInvalid), errors.Is(err, backend.ErrRejected):
+		return mcp.NewToolCallError(fmt.Sprintf("Coupon %s was not accepted: %s\n\nCall list_promotions to see the available codes.", code, err)), nil
+	default:
+		return mcp.CallToolResult{}, fmt.Errorf("failed to apply coupon: %w", err)
+	}
+}
+
+func (c *CartToolset) applyLocal(ctx context.Context, code string) (mcp.CallToolResult, error) {
+	cart, err := c.backend.GetCart(ctx)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch cart: %w", err)
+	}
+
+	discount, err := c.promotions.Apply(code, cart)
+	if errors.Is(err, promotions.ErrNotEligible) {
+		return mcp.NewToolCallError(fmt.Sprintf("Promotion %s cannot be applied: %s", strings.ToUpper(code), err)), nil
+	}
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to apply promotion: %w", err)
+	}
+
+	c.logger.InfoContext(ctx, "Applied coupon", "code", discount.Code, "source", "local", "amount", discount.Amount)
+
+	return couponResult(fmt.Sprintf("✓ Applied promotion %s: -%s", discount.Code, c.formatter().Format(discount.Amount)), c.formatCart(cart)), nil
+}
+
+func (c *CartToolset) handleRemoveCoupon(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	c.logger.InfoContext(ctx, "Removing coupon", "args", args)
+
+	code, _ := args["code"].(string)
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return mcp.NewToolCallError("code is required"), nil
+	}
+
+	var cart *models.Cart
+	var err error
+	if c.promotions.Remove(code) {
+		cart, err = c.backend.GetCart(ctx)
+	} else {
+		cart, err = c.backend.RemoveCoupon(ctx, code)
+		if unsupportedOrUnknown(err) {
+			return mcp.NewToolCallError(fmt.Sprintf("%s is not applied to the cart", code)), nil
+		}
+	}
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to remove coupon: %w", err)
+	}
+
+	c.logger.InfoContext(ctx, "Removed coupon", "code", code)
+
+	return couponResult(fmt.Sprintf("✓ Removed %s", code), c.formatCart(cart)), nil
+}
+
+func (c *CartToolset) handleListPromotions(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	c.logger.InfoContext(ctx, "Listing promotions", "args", args)
+
+	remote, err := c.backend.ListPromotions(ctx)
+	if err != nil && !unsupportedOrUnknown(err) {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch promotions: %w", err)
+	}
+	local := c.promotions.List()
+
+	if len(remote) == 0 && len(local) == 0 {
+		return couponResult("No promotions are available right now", ""), nil
+	}
+
+	applied := make(map[string]bool)
+	for _, code := range c.promotions.Applied() {
+		applied[code] = true
+	}
+	if cart, err := c.backend.GetCart(ctx); err == nil {
+		for _, d := range cart.Discounts {
+			applied[strings.ToUpper(d.Code)] = true
+		}
+	}
+
+	format := c.formatter()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🏷 Available promotions (%d):\n\n", len(remote)+len(local))
+	for _, p := range remote {
+		b.WriteString(formatPromotion(p, applied[strings.ToUpper(p.Code)], false, format))
+	}
+	for _, p := range local {
+		b.WriteString(formatPromotion(p, applied[p.Code], true, format))
+	}
+
+	return couponResult(strings.TrimSuffix(b.String(), "\n")+format.Note(), ""), nil
+}
+
+func formatPromotion(p models.Promotion, applied, local bool, format money.Formatter) string {
+	value := format.Format(p.Value) + " off"
+	if p.Type == models.PromotionPercentage {
+		value = fmt.Sprintf("%g%% off", p.Value)
+	}
+
+	var conditions []string
+	if p.CategoryId != 0 {
+		conditions = append(conditions, fmt.Sprintf("category %d only", p.CategoryId))
+	}
+	if p.MinSubtotal > 0 {
+		conditions = append(conditions, "min. "+format.Format(p.MinSubtotal))
+	}
+	if p.ExpiresAt != nil {
+		conditions = append(conditions, "until "+p.ExpiresAt.Format(time.DateOnly))
+	}
+	if local {
+		conditions = append(conditions, "local test promotion")
+	}
+	if applied {
+		conditions = append(conditions, "applied")
+	}
+
+	line := fmt.Sprintf("- **%s** - %s: %s", p.Code, p.Description, value)
+	if len(conditions) > 0 {
+		line += " (" + strings.Join(conditions, ", ") + ")"
+	}
+	return line + "\n"
+}
+
+// unsupportedOrUnknown reports whether the backend cannot handle the code,
+// either because it has no promotions support or does not know the code.
+func unsupportedOrUnknown(err error) bool {
+	return errors.Is(err, backend.ErrUnsupported) || errors.Is(err, backend.ErrNotFound)
+}
+
+func couponResult(summary, cart string) mcp.CallToolResult {
+	text := summary
+	if cart != "" {
+		text += "\n\n" + cart
+	}
+	return mcp.CallToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
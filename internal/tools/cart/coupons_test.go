@@ -0,0 +1,48 @@
+package cart
+
+import (
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/promotions"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/tooltest"
+	"testing"
+)
+
+func TestApplyCoupon(t *testing.T) {
+	reg, _ := newToolset(t, "apply_coupon", Options{})
+
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 5}`)
+
+	result := tooltest.Call(t, reg, "apply_coupon", `{"code": "WELCOME10"}`)
+	tooltest.Contains(t, result, "Applied coupon WELCOME10", "WELCOME10 - 10% off your first order: -$5.00", "Total: $44.99")
+
+	result = tooltest.Call(t, reg, "apply_coupon", `{"code": "NOPE"}`)
+	if !result.IsError {
+		t.Errorf("expected an unknown code to be refused, got %q", tooltest.Text(result))
+	}
+
+	result = tooltest.Call(t, reg, "remove_coupon", `{"code": "WELCOME10"}`)
+	tooltest.Contains(t, result, "Removed WELCOME10", "Total: $49.99")
+}
+
+func TestApplyLocalPromotion(t *testing.T) {
+	engine, err := promotions.Load("../../../fixtures/promotions.json", money.USD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg, _ := newToolset(t, "apply_local_promotion", Options{Promotions: engine})
+
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 4}`)
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 7}`)
+
+	result := tooltest.Call(t, reg, "apply_coupon", `{"code": "brewday"}`)
+	tooltest.Contains(t, result,
+		"Applied promotion BREWDAY: -$5.80",
+		"BREWDAY - 20% off brewing equipment: -$5.80 (local promotion, not yet confirmed by the store)",
+		"Total: $28.70")
+
+	result = tooltest.Call(t, reg, "list_promotions", "")
+	tooltest.Contains(t, result, "**WELCOME10**", "**BREWDAY** - 20% off brewing equipment: 20% off (category 2 only, local test promotion, applied)")
+
+	result = tooltest.Call(t, reg, "remove_coupon", `{"code": "BREWDAY"}`)
+	tooltest.Contains(t, result, "Removed BREWDAY", "Total: $34.50")
+}
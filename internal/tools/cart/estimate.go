@@ -0,0 +1,145 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/checkout"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"slices"
+	"strings"
+)
+
+// registerEstimateTools only registers estimate_checkout_total when shipping
+// or tax rules are loaded; without them it could only repeat the cart total.
+func (c *CartToolset) registerEstimateTools() {
+	if !c.checkout.HasShipping() && !c.checkout.HasTax() {
+		c.logger.InfoContext(context.Background(), "No checkout rules loaded, not registering estimate_checkout_total")
+		return
+	}
+
+	c.reg.Register(mcp.Tool{
+		Name:        "estimate_checkout_total",
+		Title:       "Estimate Checkout Total",
+		Description: "Estimate the final amount of the shopping cart before ordering: subtotal, discounts, shipping and tax for a shipping method and tax region (requires authentication)",
+		InputSchema: mcp.InputSchema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"shipping_method": {
+					Type:        "string",
+					Description: "Shipping method ID (default: the store's standard method). Unknown IDs return the available methods",
+				},
+				"region": {
+					Type:        "string",
+					Description: "Tax region code such as US-CA or DE (default: the store's home region). Unknown codes return the available regions",
+				},
+				"currency": {
+					Type:        "string",
+					Description: "Currency to show amounts in, e.g. EUR or GBP (default: the session currency set with set_currency)",
+				},
+			},
+			Required: []string{},
+		},
+		Annotations: mcp.ReadOnly(),
+	}, c.handleEstimateCheckoutTotal)
+}
+
+func (c *CartToolset) handleEstimateCheckoutTotal(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	c.logger.InfoContext(ctx, "Estimating checkout total", "args", args)
+
+	req := checkout.Request{}
+	req.ShippingMethod, _ = args["shipping_method"].(string)
+	req.Region, _ = args["region"].(string)
+	currency, _ := args["currency"].(string)
+
+	format, err := c.money.Formatter(currency)
+	if err != nil {
+		return mcp.NewToolCallError(err.Error()), nil
+	}
+
+	cart, err := c.backend.GetCart(ctx)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch cart: %w", err)
+	}
+	if len(cart.CartItems) == 0 {
+		return mcp.NewToolCallError("The cart is empty; add products before estimating the checkout total"), nil
+	}
+
+	discounts := slices.Concat(cart.Discounts, c.promotions.Discounts(cart))
+
+	estimate, err := c.checkout.Estimate(cart, discounts, req)
+	if errors.Is(err, checkout.ErrUnknownMethod) || errors.Is(err, checkout.ErrUnknownRegion) || errors.Is(err, checkout.ErrUnavailable) {
+		return mcp.NewToolCallError(fmt.Sprintf("Cannot estimate the checkout total: %s", err)), nil
+	}
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to estimate checkout total: %w", err)
+	}
+
+	c.logger.InfoContext(ctx, "Estimated checkout total", "total", estimate.Total)
+
+	return mcp.CallToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: formatEstimate(estimate, len(cart.CartItems), format),
+			},
+		},
+		StructuredContent: estimate,
+	}, nil
+}
+
+func formatEstimate(est checkout.Estimate, items int, format money.Formatter) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "🧾 Checkout estimate (%d items):\n\n", items)
+	fmt.Fprintf(&b, "Subtotal: %s\n", format.FormatMoney(est.Subtotal))
+	for _, d := range est.Discounts {
+		fmt.Fprintf(&b, "🏷 %s: -%s\n", d.Code, format.FormatMoney(d.Amount))
+	}
+
+	if est.Shipping != nil {
+		basis := shippingBasis(est, *est.Shipping, format)
+		fmt.Fprintf(&b, "Shipping, %s (%s): %s\n", est.Shipping.Name, basis, format.FormatMoney(est.Shipping.Amount))
+	} else {
+		b.WriteString("Shipping: not configured, not included\n")
+	}
+
+	if est.Tax != nil {
+		fmt.Fprintf(&b, "Tax, %s (%s): %s\n", est.Tax.Name, est.Tax.Region, format.FormatMoney(est.Tax.Amount))
+	} else {
+		b.WriteString("Tax: not configured, not included\n")
+	}
+
+	fmt.Fprintf(&b, "\n💰 Estimated total: %s\n", format.FormatMoney(est.Total))
+
+	if len(est.Alternatives) > 0 {
+		b.WriteString("\nOther shipping methods:\n")
+		for _, alt := range est.Alternatives {
+			fmt.Fprintf(&b, "- %s (%s): %s\n", alt.Name, alt.Method, format.FormatMoney(alt.Amount))
+		}
+	}
+
+	b.WriteString("\nShipping and tax are estimates; the store calculates the final amount when the order is placed.")
+	b.WriteString(format.Note())
+	return b.String()
+}
+
+func shippingBasis(est checkout.Estimate, shipping checkout.ShippingEstimate, format money.Formatter) string {
+	if shipping.Free {
+		return "free on orders over " + format.FormatMoney(shipping.FreeOver)
+	}
+
+	var basis string
+	switch shipping.Type {
+	case checkout.ShippingWeight:
+		basis = fmt.Sprintf("%g kg", est.WeightKg)
+	case checkout.ShippingPrice:
+		basis = "order value " + format.FormatMoney(est.Merchandise())
+	default:
+		basis = "flat rate"
+	}
+	if !shipping.FreeOver.IsZero() {
+		basis += ", free over " + format.FormatMoney(shipping.FreeOver)
+	}
+	return basis
+}
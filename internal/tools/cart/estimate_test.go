@@ -0,0 +1,50 @@
+package cart
+
+import (
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/checkout"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/tooltest"
+	"testing"
+)
+
+func TestEstimateCheckoutTotal(t *testing.T) {
+	rules, err := checkout.Load("../../../fixtures/checkout.json", money.USD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg, _ := newToolset(t, "estimate_checkout_total", Options{Checkout: rules})
+
+	result := tooltest.Call(t, reg, "estimate_checkout_total", "")
+	if !result.IsError {
+		t.Errorf("expected a tool error for an empty cart, got %q", tooltest.Text(result))
+	}
+
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 2}`)
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 4}`)
+
+	result = tooltest.Call(t, reg, "estimate_checkout_total", "")
+	tooltest.Contains(t, result,
+		"Subtotal: $41.90",
+		"Shipping, Standard (3-5 days) (1.05 kg, free over $75.00): $8.95",
+		"Tax, California (US-CA): $2.10",
+		"Estimated total: $52.95",
+		"Express (next day) (express): $19.95")
+
+	result = tooltest.Call(t, reg, "estimate_checkout_total", `{"shipping_method": "express", "region": "DE"}`)
+	tooltest.Contains(t, result, "Shipping, Express (next day) (flat rate): $19.95", "Tax, Germany (DE)")
+
+	result = tooltest.Call(t, reg, "estimate_checkout_total", `{"region": "MARS"}`)
+	if !result.IsError {
+		t.Errorf("expected a tool error for an unknown region, got %q", tooltest.Text(result))
+	}
+}
+
+func TestEstimateCheckoutTotalNeedsRules(t *testing.T) {
+	reg := mcp.NewRegistry(tooltest.Logger())
+	NewCartToolset(reg, nil, Options{}, tooltest.Logger())
+
+	if reg.Has("estimate_checkout_total") {
+		t.Error("expected estimate_checkout_total not to be registered without checkout rules")
+	}
+}
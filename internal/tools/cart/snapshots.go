@@ -0,0 +1,459 @@
+package cart
+
+import (
+	"context"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/promotions"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultSnapshotLimit = 20
+
+type SnapshotItem struct {
+	ProductID int     `json:"product_id"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"`
+}
+
+// Snapshot is the cart as it was right before Tool changed it. Coupons are
+// the codes the store had applied and Promotions the locally applied ones.
+type Snapshot struct {
+	ID         int            `json:"id"`
+	Tool       string         `json:"tool"`
+	TakenAt    time.Time      `json:"taken_at"`
+	Items      []SnapshotItem `json:"items"`
+	Coupons    []string       `json:"coupons,omitempty"`
+	Promotions []string       `json:"promotions,omitempty"`
+	Total      float64        `json:"total"`
+}
+
+// Snapshots keeps the most recent cart snapshots of the session, oldest
+// first. It is shared by every toolset that changes the cart.
+type Snapshots struct {
+	mu         sync.Mutex
+	limit      int
+	nextID     int
+	list       []Snapshot
+	promotions *promotions.Engine
+	logger     *slog.Logger
+}
+
+// Track wraps a mutating cart tool so the cart is recorded before it runs.
+// The snapshot is dropped again when the tool fails without changing the cart.
+// When the cart cannot be read beforehand the tool still runs, without a
+// snapshot.
+func (s *Snapshots) Track(store backend.Cart, tool string, handler mcp.ToolFunc) mcp.ToolFunc {
+	return func(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+		before, err := store.GetCart(ctx)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Failed to snapshot cart, running tool without a snapshot", "tool", tool, "error", err)
+			return handler(ctx, args)
+		}
+		id := s.record(tool, before)
+		key := s.stateKey(before)
+
+		result, err := handler(ctx, args)
+		if err != nil || result.IsError {
+			if after, getErr := store.GetCart(ctx); getErr == nil && s.stateKey(after) == key {
+				s.Discard(id)
+			}
+		}
+		return result, err
+	}
+}
+
+func (s *Snapshots) record(tool string, cart *models.Cart) int {
+	promotions := s.applied()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	snapshot := Snapshot{
+		ID:         s.nextID,
+		Tool:       tool,
+		TakenAt:    time.Now(),
+		Items:      make([]SnapshotItem, 0, len(cart.CartItems)),
+		Coupons:    couponCodes(cart),
+		Promotions: promotions,
+		Total:      cart.Total,
+	}
+	for _, item := range cart.CartItems {
+		snapshot.Items = append(snapshot.Items, SnapshotItem{
+			ProductID: item.Product.Id,
+			Name:      item.Product.Name,
+			Quantity:  item.Quantity,
+			Price:     item.Product.Price,
+		})
+	}
+
+	s.list = append(s.list, snapshot)
+	if len(s.list) > s.limit {
+		s.list = slices.Delete(s.list, 0, len(s.list)-s.limit)
+	}
+	return snapshot.ID
+}
+
+// List returns the snapshots newest first.
+func (s *Snapshots) List() []Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := slices.Clone(s.list)
+	slices.Reverse(list)
+	return list
+}
+
+func (s *Snapshots) Get(id int) (Snapshot, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := slices.IndexFunc(s.list, func(snapshot Snapshot) bool { return snapshot.ID == id })
+	if idx < 0 {
+		return Snapshot{}, false
+	}
+	return s.list[idx], true
+}
+
+func (s *Snapshots) Latest() (Snapshot, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.list) == 0 {
+		return Snapshot{}, false
+	}
+	return s.list[len(s.list)-1], true
+}
+
+func (s *Snapshots) Discard(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.list = slices.DeleteFunc(s.list, func(snapshot Snapshot) bool { return snapshot.ID == id })
+}
+
+// Clear forgets every snapshot, for when the cart has been turned into an
+// order and restoring it would re-add ordered items.
+func (s *Snapshots) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.list = nil
+}
+
+// applied returns the locally applied promotion codes, if the snapshots know
+// the promotion engine.
+func (s *Snapshots) applied() []string {
+	if s.promotions == nil {
+		return nil
+	}
+	return s.promotions.Applied()
+}
+
+// stateKey identifies the cart lines and applied codes, to tell whether a
+// failed tool changed anything.
+func (s *Snapshots) stateKey(cart *models.Cart) string {
+	quantities := make([]string, 0, len(cart.CartItems))
+	for _, item := range cart.CartItems {
+		quantities = append(quantities, fmt.Sprintf("%d:%d", item.Product.Id, item.Quantity))
+	}
+	slices.Sort(quantities)
+
+	codes := append(couponCodes(cart), s.applied()...)
+	slices.Sort(codes)
+	return strings.Join(quantities, ",") + "|" + strings.Join(codes, ",")
+}
+
+func couponCodes(cart *models.Cart) []string {
+	codes := make([]string, 0, len(cart.Discounts))
+	for _, d := range cart.Discounts {
+		codes = append(codes, d.Code)
+	}
+	return codes
+}
+
+func (c *CartToolset) registerSnapshotTools() {
+	c.reg.Register(mcp.Tool{
+		Name:        "undo_last_cart_change",
+		Title:       "Undo Last Cart Change",
+		Description: "Undo the most recent change made to the shopping cart by a cart tool, restoring the cart to how it was before. Call it again to undo earlier changes (requires authentication)",
+		InputSchema: mcp.InputSchema{
+			Type:       "object",
+			Properties: map[string]mcp.Property{},
+			Required:   []string{},
+		},
+		Annotations: mcp.Mutating(true, false),
+	}, c.handleUndoLastCartChange)
+
+	c.reg.Register(mcp.Tool{
+		Name:        "list_cart_snapshots",
+		Title:       "List Cart Snapshots",
+		Description: "List the snapshots of the shopping cart taken before each recent cart change, newest first",
+		InputSchema: mcp.InputSchema{
+			Type:       "object",
+			Properties: map[string]mcp.Property{},
+			Required:   []string{},
+		},
+		Annotations: mcp.ReadOnly(),
+	}, c.handleListCartSnapshots)
+
+	c.reg.Register(mcp.Tool{
+		Name:        "restore_cart_snapshot",
+		Title:       "Restore Cart Snapshot",
+		Description: "Restore the shopping cart to a snapshot from list_cart_snapshots, adding, updating and removing lines as needed (requires authentication)",
+		InputSchema: mcp.InputSchema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"snapshot_id": {
+					Type:        "number",
+					Description: "ID of the snapshot to restore",
+				},
+			},
+			Required: []string{"snapshot_id"},
+		},
+		Annotations: mcp.Mutating(true, true),
+	}, c.snapshots.Track(c.backend, "restore_cart_snapshot", c.handleRestoreCartSnapshot))
+}
+
+func (c *CartToolset) handleUndoLastCartChange(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	c.logger.InfoContext(ctx, "Undoing last cart change", "args", args)
+
+	snapshot, ok := c.snapshots.Latest()
+	if !ok {
+		return mcp.NewToolCallError("There is no cart change to undo"), nil
+	}
+
+	cart, changes, failures, err := c.reconcile(ctx, snapshot)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to undo cart change: %w", err)
+	}
+	// A partly undone change stays on top, so it can be retried or restored.
+	if len(failures) == 0 {
+		c.snapshots.Discard(snapshot.ID)
+	}
+
+	c.logger.InfoContext(ctx, "Undid cart change", "snapshot_id", snapshot.ID, "tool", snapshot.Tool, "changes", len(changes), "failures", len(failures))
+
+	summary := fmt.Sprintf("↩ Undid %s", snapshot.Tool)
+	return reconcileResult(summary, changes, failures, c.formatCart(cart)), nil
+}
+
+func (c *CartToolset) handleListCartSnapshots(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	c.logger.InfoContext(ctx, "Listing cart snapshots", "args", args)
+
+	snapshots := c.snapshots.List()
+	if len(snapshots) == 0 {
+		return snapshotResult("No cart snapshots yet; one is taken before every cart change"), nil
+	}
+
+	format := c.formatter()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📸 Cart snapshots (%d, newest first):\n\n", len(snapshots))
+	for _, snapshot := range snapshots {
+		fmt.Fprintf(&b, "- Snapshot %d, before %s at %s: ", snapshot.ID, snapshot.Tool, snapshot.TakenAt.Format(time.TimeOnly))
+		if len(snapshot.Items) == 0 {
+			b.WriteString("empty cart\n")
+			continue
+		}
+		lines := make([]string, 0, len(snapshot.Items))
+		for _, item := range snapshot.Items {
+			lines = append(lines, fmt.Sprintf("%s × %d", item.Name, item.Quantity))
+		}
+		fmt.Fprintf(&b, "%s (total %s", strings.Join(lines, ", "), format.Format(snapshot.Total))
+		if codes := append(slices.Clone(snapshot.Coupons), snapshot.Promotions...); len(codes) > 0 {
+			fmt.Fprintf(&b, ", codes %s", strings.Join(codes, ", "))
+		}
+		b.WriteString(")\n")
+	}
+
+	return snapshotResult(strings.TrimSuffix(b.String(), "\n") + format.Note()), nil
+}
+
+func (c *CartToolset) handleRestoreCartSnapshot(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	c.logger.InfoContext(ctx, "Restoring cart snapshot", "args", args)
+
+	idFloat, ok := args["snapshot_id"].(float64)
+	if !ok {
+		return mcp.CallToolResult{}, fmt.Errorf("invalid snapshot_id; %+v", args["snapshot_id"])
+	}
+
+	snapshot, ok := c.snapshots.Get(int(idFloat))
+	if !ok {
+		return mcp.NewToolCallError(fmt.Sprintf("Snapshot %d does not exist; call list_cart_snapshots to see the available snapshots", int(idFloat))), nil
+	}
+
+	cart, changes, failures, err := c.reconcile(ctx, snapshot)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to restore cart snapshot: %w", err)
+	}
+
+	c.logger.InfoContext(ctx, "Restored cart snapshot", "snapshot_id", snapshot.ID, "changes", len(changes), "failures", len(failures))
+
+	summary := fmt.Sprintf("↩ Restored snapshot %d (before %s)", snapshot.ID, snapshot.Tool)
+	return reconcileResult(summary, changes, failures, c.formatCart(cart)), nil
+}
+
+// reconcile changes the live cart until it holds the snapshot's lines and
+// codes. Lines and codes that cannot be restored, such as products that went
+// out of stock, are reported as failures while the rest of the cart is still
+// reconciled.
+func (c *CartToolset) reconcile(ctx context.Context, snapshot Snapshot) (*models.Cart, []string, []string, error) {
+	live, err := c.backend.GetCart(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to fetch cart: %w", err)
+	}
+
+	var changes, failures []string
+
+	if len(snapshot.Items) == 0 && len(live.CartItems) > 0 {
+		if _, err := c.backend.ClearCart(ctx); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to clear cart: %w", err)
+		}
+		changes = append(changes, "cleared the cart")
+	} else {
+		want := make(map[int]SnapshotItem, len(snapshot.Items))
+		for _, item := range snapshot.Items {
+			want[item.ProductID] = item
+		}
+
+		for _, item := range live.CartItems {
+			target, keep := want[item.Product.Id]
+			delete(want, item.Product.Id)
+
+			switch {
+			case !keep:
+				if _, err := c.backend.RemoveCartItem(ctx, item.Id); err != nil {
+					failures = append(failures, fmt.Sprintf("could not remove %s: %s", item.Product.Name, err))
+					continue
+				}
+				changes = append(changes, fmt.Sprintf("removed %s", item.Product.Name))
+			case item.Quantity != target.Quantity:
+				if _, err := c.backend.UpdateCartItem(ctx, item.Id, target.Quantity); err != nil {
+					failures = append(failures, fmt.Sprintf("could not set %s to %d: %s", item.Product.Name, target.Quantity, err))
+					continue
+				}
+				changes = append(changes, fmt.Sprintf("set %s from %d to %d", item.Product.Name, item.Quantity, target.Quantity))
+			}
+		}
+
+		for _, item := range snapshot.Items {
+			if _, missing := want[item.ProductID]; !missing {
+				continue
+			}
+			if _, err := c.backend.AddToCart(ctx, item.ProductID, item.Quantity); err != nil {
+				failures = append(failures, fmt.Sprintf("could not re-add %s × %d: %s", item.Name, item.Quantity, err))
+				continue
+			}
+			changes = append(changes, fmt.Sprintf("re-added %s × %d", item.Name, item.Quantity))
+		}
+	}
+
+	cart, err := c.backend.GetCart(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to fetch cart: %w", err)
+	}
+
+	// Codes are restored once the lines are, since eligibility depends on them.
+	liveCoupons := couponCodes(cart)
+	for _, code := range liveCoupons {
+		if slices.Contains(snapshot.Coupons, code) {
+			continue
+		}
+		updated, err := c.backend.RemoveCoupon(ctx, code)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("could not remove coupon %s: %s", code, err))
+			continue
+		}
+		cart = updated
+		changes = append(changes, fmt.Sprintf("removed coupon %s", code))
+	}
+	for _, code := range snapshot.Coupons {
+		if slices.Contains(liveCoupons, code) {
+			continue
+		}
+		updated, err := c.backend.ApplyCoupon(ctx, code)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("could not re-apply coupon %s: %s", code, err))
+			continue
+		}
+		cart = updated
+		changes = append(changes, fmt.Sprintf("re-applied coupon %s", code))
+	}
+
+	applied := c.promotions.Applied()
+	for _, code := range applied {
+		if !slices.Contains(snapshot.Promotions, code) {
+			c.promotions.Remove(code)
+			changes = append(changes, fmt.Sprintf("removed promotion %s", code))
+		}
+	}
+	for _, code := range snapshot.Promotions {
+		if slices.Contains(applied, code) {
+			continue
+		}
+		if _, err := c.promotions.Apply(code, cart); err != nil {
+			failures = append(failures, fmt.Sprintf("could not re-apply promotion %s: %s", code, err))
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("re-applied promotion %s", code))
+	}
+
+	return cart, changes, failures, nil
+}
+
+func reconcileResult(summary string, changes, failures []string, cart string) mcp.CallToolResult {
+	var b strings.Builder
+	b.WriteString(summary)
+	if len(changes) == 0 && len(failures) == 0 {
+		b.WriteString("\n\nThe cart already matched; nothing changed.")
+	}
+	for _, change := range changes {
+		fmt.Fprintf(&b, "\n- %s", change)
+	}
+	if len(failures) > 0 {
+		b.WriteString("\n\n⚠ Some lines could not be restored:")
+		for _, failure := range failures {
+			fmt.Fprintf(&b, "\n- %s", failure)
+		}
+	}
+	fmt.Fprintf(&b, "\n\n%s", cart)
+
+	return mcp.CallToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: b.String(),
+			},
+		},
+		IsError: len(failures) > 0 && len(changes) == 0,
+	}
+}
+
+func snapshotResult(text string) mcp.CallToolResult {
+	return mcp.CallToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+// NewSnapshots keeps up to limit snapshots. With an engine, snapshots also
+// record the locally applied promotion codes.
+func NewSnapshots(limit int, engine *promotions.Engine, logger *slog.Logger) *Snapshots {
+	if limit <= 0 {
+		limit = DefaultSnapshotLimit
+	}
+	return &Snapshots{limit: limit, promotions: engine, logger: logger}
+}
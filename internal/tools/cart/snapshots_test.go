@@ -0,0 +1,88 @@
+package cart
+
+import (
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/promotions"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/tooltest"
+	"testing"
+)
+
+func TestUndoLastCartChange(t *testing.T) {
+	reg, _ := newToolset(t, "undo_last_cart_change", Options{})
+
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 3}`)
+	tooltest.Call(t, reg, "update_cart_item", `{"product_id": 3, "quantity": 4}`)
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 8}`)
+
+	result := tooltest.Call(t, reg, "undo_last_cart_change", "")
+	tooltest.Contains(t, result, "Undid add_to_cart", "removed Stoneware Mug", "product 3) - $21.00 × 4 = $84.00")
+
+	result = tooltest.Call(t, reg, "undo_last_cart_change", "")
+	tooltest.Contains(t, result, "Undid update_cart_item", "set Espresso Blend Whole Beans 1kg from 4 to 1")
+
+	result = tooltest.Call(t, reg, "undo_last_cart_change", "")
+	tooltest.Contains(t, result, "Undid add_to_cart", "cleared the cart", "Your cart is empty")
+
+	result = tooltest.Call(t, reg, "undo_last_cart_change", "")
+	if !result.IsError {
+		t.Errorf("expected nothing left to undo, got %q", tooltest.Text(result))
+	}
+}
+
+func TestRestoreCartSnapshot(t *testing.T) {
+	reg, _ := newToolset(t, "restore_cart_snapshot", Options{})
+
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 1}`)
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 5}`)
+	tooltest.Call(t, reg, "clear_cart", "")
+
+	result := tooltest.Call(t, reg, "list_cart_snapshots", "")
+	tooltest.Contains(t, result,
+		"Cart snapshots (3, newest first)",
+		"Snapshot 3, before clear_cart",
+		"Ethiopia Yirgacheffe Whole Beans 1kg × 1, Gooseneck Kettle 1L × 1 (total $74.49)",
+		"Snapshot 1, before add_to_cart")
+
+	result = tooltest.Call(t, reg, "restore_cart_snapshot", `{"snapshot_id": 3}`)
+	tooltest.Contains(t, result,
+		"Restored snapshot 3 (before clear_cart)",
+		"re-added Ethiopia Yirgacheffe Whole Beans 1kg × 1",
+		"re-added Gooseneck Kettle 1L × 1",
+		"Total: $74.49")
+
+	result = tooltest.Call(t, reg, "restore_cart_snapshot", `{"snapshot_id": 42}`)
+	if !result.IsError {
+		t.Errorf("expected a tool error for an unknown snapshot, got %q", tooltest.Text(result))
+	}
+}
+
+func TestUndoCouponChanges(t *testing.T) {
+	engine, err := promotions.Load("../../../fixtures/promotions.json", money.USD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg, _ := newToolset(t, "undo_coupon_changes", Options{Promotions: engine})
+
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 4}`)
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 7}`)
+	tooltest.Call(t, reg, "apply_coupon", `{"code": "WELCOME10"}`)
+	tooltest.Call(t, reg, "apply_coupon", `{"code": "BREWDAY"}`)
+
+	result := tooltest.Call(t, reg, "remove_coupon", `{"code": "WELCOME10"}`)
+	tooltest.Contains(t, result, "Removed WELCOME10")
+
+	result = tooltest.Call(t, reg, "list_cart_snapshots", "")
+	tooltest.Contains(t, result, "Snapshot 5, before remove_coupon", "codes WELCOME10, BREWDAY)")
+
+	result = tooltest.Call(t, reg, "undo_last_cart_change", "")
+	tooltest.Contains(t, result, "Undid remove_coupon", "re-applied coupon WELCOME10", "WELCOME10 - 10% off your first order")
+
+	result = tooltest.Call(t, reg, "undo_last_cart_change", "")
+	tooltest.Contains(t, result, "Undid apply_coupon", "removed promotion BREWDAY")
+	if engine.IsApplied("BREWDAY") {
+		t.Error("expected BREWDAY to be removed by the undo")
+	}
+
+	result = tooltest.Call(t, reg, "undo_last_cart_change", "")
+	tooltest.Contains(t, result, "Undid apply_coupon", "removed coupon WELCOME10")
+}
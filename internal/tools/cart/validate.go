@@ -0,0 +1,246 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"strings"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+const (
+	ProblemEmptyCart          = "empty_cart"
+	ProblemProductUnavailable = "product_unavailable"
+	ProblemInactiveProduct    = "inactive_product"
+	ProblemInvalidQuantity    = "invalid_quantity"
+	ProblemInsufficientStock  = "insufficient_stock"
+	ProblemPriceChanged       = "price_changed"
+	ProblemSubtotalMismatch   = "subtotal_mismatch"
+	ProblemTotalMismatch      = "total_mismatch"
+	ProblemLocalPromotion     = "local_promotion"
+)
+
+// Problem carries the amounts of price and total problems in Expected and
+// Actual, and the quantities of stock problems in Stock and Quantity.
+type Problem struct {
+	Code      string       `json:"code"`
+	Severity  string       `json:"severity"`
+	ItemID    int          `json:"item_id,omitempty"`
+	ProductID int          `json:"product_id,omitempty"`
+	Product   string       `json:"product,omitempty"`
+	Message   string       `json:"message"`
+	Fix       string       `json:"fix"`
+	Expected  *money.Money `json:"expected,omitempty"`
+	Actual    *money.Money `json:"actual,omitempty"`
+	Stock     int          `json:"stock,omitempty"`
+	Quantity  int          `json:"quantity,omitempty"`
+}
+
+// Report holds the cart total in the store currency.
+type Report struct {
+	Valid     bool        `json:"valid"`
+	ItemCount int         `json:"item_count"`
+	Total     money.Money `json:"total"`
+	Problems  []Problem   `json:"problems"`
+}
+
+// Blocking returns the problems that must be fixed before ordering. Warnings
+// only block until the user has accepted them.
+func (r Report) Blocking(acceptWarnings bool) []Problem {
+	blocking := make([]Problem, 0, len(r.Problems))
+	for _, p := range r.Problems {
+		if p.Severity == SeverityError || !acceptWarnings {
+			blocking = append(blocking, p)
+		}
+	}
+	return blocking
+}
+
+type Validator struct {
+	catalog  backend.Catalog
+	currency string
+}
+
+// Validate re-fetches every product in the cart and compares it with the
+// cart line, so problems surface before the backend rejects the order.
+func (v *Validator) Validate(ctx context.Context, cart *models.Cart) (Report, error) {
+	report := Report{
+		ItemCount: len(cart.CartItems),
+		Total:     cart.TotalIn(v.currency),
+		Problems:  []Problem{},
+	}
+
+	if len(cart.CartItems) == 0 {
+		report.Problems = append(report.Problems, Problem{
+			Code:     ProblemEmptyCart,
+			Severity: SeverityError,
+			Message:  "The cart is empty",
+			Fix:      "Add products with add_to_cart before ordering",
+		})
+		return report, nil
+	}
+
+	sum := money.Zero(v.currency)
+	for _, item := range cart.CartItems {
+		sum = sum.Add(item.SubtotalIn(v.currency))
+
+		problems, err := v.validateItem(ctx, item)
+		if err != nil {
+			return Report{}, err
+		}
+		report.Problems = append(report.Problems, problems...)
+	}
+
+	if total := report.Total; sum != total {
+		report.Problems = append(report.Problems, Problem{
+			Code:     ProblemTotalMismatch,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("Cart total %s does not match the sum of its lines %s", total, sum),
+			Fix:      "Call view_cart to refresh the cart; if the mismatch remains, remove and re-add the affected items",
+			Expected: &sum,
+			Actual:   &total,
+		})
+	}
+
+	report.Valid = len(report.Problems) == 0
+	return report, nil
+}
+
+func (v *Validator) validateItem(ctx context.Context, item models.CartItem) ([]Problem, error) {
+	base := Problem{
+		ItemID:    item.Id,
+		ProductID: item.Product.Id,
+		Product:   item.Product.Name,
+	}
+	problem := func(code, severity, message, fix string) Problem {
+		p := base
+		p.Code, p.Severity, p.Message, p.Fix = code, severity, message, fix
+		return p
+	}
+
+	current, err := v.catalog.GetProduct(ctx, item.Product.Id)
+	if errors.Is(err, backend.ErrNotFound) {
+		return []Problem{problem(ProblemProductUnavailable, SeverityError,
+			fmt.Sprintf("%s no longer exists in the catalog", item.Product.Name),
+			fmt.Sprintf("Remove it with remove_from_cart (item_id %d)", item.Id))}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch product %d: %w", item.Product.Id, err)
+	}
+
+	if !current.IsActive {
+		return []Problem{problem(ProblemInactiveProduct, SeverityError,
+			fmt.Sprintf("%s is no longer available", current.Name),
+			fmt.Sprintf("Remove it with remove_from_cart (item_id %d) and suggest an alternative from search_products", item.Id))}, nil
+	}
+
+	var problems []Problem
+
+	if item.Quantity <= 0 {
+		problems = append(problems, problem(ProblemInvalidQuantity, SeverityError,
+			fmt.Sprintf("%s has an invalid quantity of %d", current.Name, item.Quantity),
+			fmt.Sprintf("Set a quantity with update_cart_item (item_id %d) or remove it", item.Id)))
+		return problems, nil
+	}
+
+	if item.Quantity > current.Stock {
+		fix := fmt.Sprintf("Lower the quantity to %d with update_cart_item (item_id %d)", current.Stock, item.Id)
+		if current.Stock == 0 {
+			fix = fmt.Sprintf("It is out of stock; remove it with remove_from_cart (item_id %d)", item.Id)
+		}
+		p := problem(ProblemInsufficientStock, SeverityError,
+			fmt.Sprintf("%d of %s requested but only %d in stock", item.Quantity, current.Name, current.Stock), fix)
+		p.Stock, p.Quantity = current.Stock, item.Quantity
+		problems = append(problems, p)
+	}
+
+	// Backends that do not report the price a line was added at cannot show
+	// a price change.
+	added, now := item.PriceIn(v.currency), current.PriceIn(v.currency)
+	unit, subtotal := item.Product.PriceIn(v.currency), item.SubtotalIn(v.currency)
+	if !added.IsZero() && added != now {
+		p := problem(ProblemPriceChanged, SeverityWarning,
+			fmt.Sprintf("%s was added at %s but now costs %s", current.Name, added, now),
+			"Tell the user about the new price and get their confirmation before ordering")
+		p.Expected, p.Actual = &added, &now
+		problems = append(problems, p)
+	} else if expected := unit.Mul(item.Quantity); expected != subtotal {
+		p := problem(ProblemSubtotalMismatch, SeverityWarning,
+			fmt.Sprintf("%s subtotal %s does not match %s × %d", current.Name, subtotal, unit, item.Quantity),
+			fmt.Sprintf("Call view_cart to refresh the cart; if it persists, remove and re-add item_id %d", item.Id))
+		p.Expected, p.Actual = &expected, &subtotal
+		problems = append(problems, p)
+	}
+
+	return problems, nil
+}
+
+func (c *CartToolset) registerValidateTools() {
+	c.reg.Register(mcp.Tool{
+		Name:        "validate_cart",
+		Title:       "Validate Cart",
+		Description: "Check the shopping cart against the current catalog before checkout: unavailable products, quantities above stock, price changes and total mismatches, each with a suggested fix (requires authentication)",
+		InputSchema: mcp.InputSchema{
+			Type:       "object",
+			Properties: map[string]mcp.Property{},
+			Required:   []string{},
+		},
+		Annotations: mcp.ReadOnly(),
+	}, c.handleValidateCart)
+}
+
+func (c *CartToolset) handleValidateCart(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	c.logger.InfoContext(ctx, "Validating cart", "args", args)
+
+	cart, err := c.backend.GetCart(ctx)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch cart: %w", err)
+	}
+
+	report, err := c.validator.Validate(ctx, cart)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to validate cart: %w", err)
+	}
+
+	c.logger.InfoContext(ctx, "Validated cart", "valid", report.Valid, "problems", len(report.Problems))
+
+	return mcp.CallToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: FormatReport(report),
+			},
+		},
+		StructuredContent: report,
+	}, nil
+}
+
+func FormatReport(report Report) string {
+	if report.Valid {
+		return fmt.Sprintf("✓ Cart is ready for checkout (%d items, total %s)", report.ItemCount, report.Total)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Cart has %d problem(s):\n\n", len(report.Problems))
+	for i, p := range report.Problems {
+		icon := "✗"
+		if p.Severity == SeverityWarning {
+			icon = "⚠"
+		}
+		fmt.Fprintf(&b, "%d. %s [%s] %s\n   Fix: %s\n", i+1, icon, p.Code, p.Message, p.Fix)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// NewValidator reports amounts in currency, the currency of the store.
+func NewValidator(catalog backend.Catalog, currency string) *Validator {
+	return &Validator{catalog: catalog, currency: currency}
+}
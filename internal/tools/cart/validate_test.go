@@ -0,0 +1,88 @@
+package cart
+
+import (
+	"context"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/tooltest"
+	"testing"
+)
+
+func TestValidateCart(t *testing.T) {
+	reg, _ := newToolset(t, "validate_cart", Options{})
+
+	result := tooltest.Call(t, reg, "validate_cart", "")
+	tooltest.Contains(t, result, "Cart has 1 problem(s)", "[empty_cart]")
+
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 5, "quantity": 2}`)
+
+	result = tooltest.Call(t, reg, "validate_cart", "")
+	tooltest.Contains(t, result, "Cart is ready for checkout (1 items, total $99.98)")
+	report, ok := result.StructuredContent.(Report)
+	if !ok || !report.Valid {
+		t.Errorf("expected a valid report, got %+v", result.StructuredContent)
+	}
+}
+
+// catalog serves fixed products, standing in for a catalog whose prices
+// changed after the cart was filled.
+type catalog map[int]models.Product
+
+func (c catalog) ListProducts(context.Context, backend.ProductQuery) ([]models.Product, error) {
+	return nil, backend.ErrUnsupported
+}
+
+func (c catalog) SearchProducts(context.Context, backend.SearchQuery) ([]models.Product, error) {
+	return nil, backend.ErrUnsupported
+}
+
+func (c catalog) GetProduct(_ context.Context, id int) (*models.Product, error) {
+	p, ok := c[id]
+	if !ok {
+		return nil, backend.ErrNotFound
+	}
+	return &p, nil
+}
+
+func (c catalog) GetProductBySKU(context.Context, string) (*models.Product, error) {
+	return nil, backend.ErrUnsupported
+}
+
+func TestValidatorReportsPriceChange(t *testing.T) {
+	kettle := models.Product{Id: 5, Name: "Gooseneck Kettle 1L", Price: 54.99, Stock: 7, IsActive: true}
+	mug := models.Product{Id: 8, Name: "Stoneware Mug", Price: 14, Stock: 25, IsActive: true}
+	validator := NewValidator(catalog{5: kettle, 8: mug}, "USD")
+
+	// The subtotals already follow the new catalog price, as backends
+	// recalculate them; only the price at add time shows the change.
+	cart := &models.Cart{
+		CartItems: []models.CartItem{
+			{Id: 1, Product: kettle, Quantity: 2, Price: 49.99, Subtotal: 109.98},
+			{Id: 2, Product: mug, Quantity: 1, Price: 14, Subtotal: 14},
+		},
+		Total: 123.98,
+	}
+
+	report, err := validator.Validate(t.Context(), cart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Problems) != 1 {
+		t.Fatalf("expected one problem, got %+v", report.Problems)
+	}
+	p := report.Problems[0]
+	if p.Code != ProblemPriceChanged || p.ItemID != 1 {
+		t.Errorf("unexpected problem %+v", p)
+	}
+	// Expected is the price the item was added at, Actual the price now.
+	if p.Expected == nil || *p.Expected != (money.Money{Amount: 4999, Currency: "USD"}) {
+		t.Errorf("expected the price at add time $49.99 in Expected, got %v", p.Expected)
+	}
+	if p.Actual == nil || *p.Actual != (money.Money{Amount: 5499, Currency: "USD"}) {
+		t.Errorf("expected the current price $54.99 in Actual, got %v", p.Actual)
+	}
+	if len(report.Blocking(true)) != 0 {
+		t.Errorf("a price change should not block once accepted")
+	}
+}
@@ -0,0 +1,75 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"log/slog"
+	"strings"
+)
+
+type CurrencyToolset struct {
+	reg    *mcp.Registry
+	logger *slog.Logger
+	money  *money.Display
+}
+
+func (c *CurrencyToolset) registerTools() {
+	c.reg.Register(mcp.Tool{
+		Name:        "set_currency",
+		Title:       "Set Currency",
+		Description: "Set the currency prices and totals are shown in for the rest of the session. The store still charges in its own currency; converted amounts are estimates",
+		InputSchema: mcp.InputSchema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"currency": {
+					Type:        "string",
+					Description: "ISO currency code such as USD, EUR or GBP",
+				},
+			},
+			Required: []string{"currency"},
+		},
+		Annotations: mcp.Mutating(false, true),
+	}, c.handleSetCurrency)
+}
+
+func (c *CurrencyToolset) handleSetCurrency(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	c.logger.InfoContext(ctx, "Setting currency", "args", args)
+
+	code, _ := args["currency"].(string)
+	if err := c.money.SetCurrency(code); errors.Is(err, money.ErrUnknownCurrency) {
+		return mcp.NewToolCallError(fmt.Sprintf("Cannot show prices in that currency: %s", err)), nil
+	} else if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to set currency: %w", err)
+	}
+
+	currency := c.money.Currency()
+	c.logger.InfoContext(ctx, "Set currency", "currency", currency)
+
+	text := fmt.Sprintf("✓ Prices are now shown in %s", currency)
+	if format, err := c.money.Formatter(""); err == nil && format.Converted() {
+		text += format.Note()
+	}
+	text += fmt.Sprintf("\n\nAvailable currencies: %s", strings.Join(c.money.Currencies(), ", "))
+
+	return mcp.CallToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}, nil
+}
+
+func NewCurrencyToolset(reg *mcp.Registry, display *money.Display, logger *slog.Logger) *CurrencyToolset {
+	ct := &CurrencyToolset{
+		reg:    reg,
+		money:  display,
+		logger: logger,
+	}
+	ct.registerTools()
+	return ct
+}
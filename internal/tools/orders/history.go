@@ -0,0 +1,356 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"slices"
+	"strings"
+	"time"
+)
+
+const defaultOrderLimit = 10
+
+// orderStatuses are the statuses the store is known to use; others are still
+// passed on to the backend.
+var orderStatuses = []string{
+	models.OrderPending,
+	models.OrderPaid,
+	models.OrderProcessing,
+	models.OrderShipped,
+	models.OrderDelivered,
+	models.OrderCancelled,
+}
+
+func (o *OrderToolset) registerHistoryTools() {
+	o.reg.Register(mcp.Tool{
+		Name:        "list_orders",
+		Title:       "List Orders",
+		Description: "List the user's past orders, newest first, optionally filtered by status and date range (requires authentication)",
+		InputSchema: mcp.InputSchema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"limit": {
+					Type:        "number",
+					Description: "Maximum number of orders to return (default: 10)",
+				},
+				"offset": {
+					Type:        "number",
+					Description: "Number of orders to skip (default: 0)",
+				},
+				"status": {
+					Type:        "string",
+					Description: "Only return orders with this status, such as " + strings.Join(orderStatuses, ", "),
+				},
+				"from": {
+					Type:        "string",
+					Description: "Only return orders placed on or after this date (YYYY-MM-DD or RFC 3339)",
+				},
+				"to": {
+					Type:        "string",
+					Description: "Only return orders placed on or before this date or time (YYYY-MM-DD or RFC 3339)",
+				},
+				"currency": {
+					Type:        "string",
+					Description: "Currency to show amounts in, e.g. EUR or GBP (default: the session currency set with set_currency)",
+				},
+			},
+			Required: []string{},
+		},
+		Annotations: mcp.ReadOnly(),
+	}, o.handleListOrders)
+
+	o.reg.Register(mcp.Tool{
+		Name:        "get_order_details",
+		Title:       "Get Order Details",
+		Description: "Get a past order with its items, amounts, status timeline and shipping and tracking information (requires authentication)",
+		InputSchema: mcp.InputSchema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"order_id": {
+					Type:        "number",
+					Description: "ID of the order, as shown by list_orders",
+				},
+				"currency": {
+					Type:        "string",
+					Description: "Currency to show amounts in, e.g. EUR or GBP (default: the session currency set with set_currency)",
+				},
+			},
+			Required: []string{"order_id"},
+		},
+		Annotations: mcp.ReadOnly(),
+	}, o.handleGetOrderDetails)
+}
+
+func (o *OrderToolset) handleListOrders(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	o.logger.InfoContext(ctx, "Listing orders", "args", args)
+
+	currency, _ := args["currency"].(string)
+	format, err := o.money.Formatter(currency)
+	if err != nil {
+		return mcp.NewToolCallError(err.Error()), nil
+	}
+
+	q := backend.OrderQuery{Limit: defaultOrderLimit}
+	if l, ok := args["limit"].(float64); ok && l >= 1 {
+		q.Limit = int(l)
+	}
+	if off, ok := args["offset"].(float64); ok && off >= 0 {
+		q.Offset = int(off)
+	}
+
+	status, _ := args["status"].(string)
+	q.Status = strings.ToLower(strings.TrimSpace(status))
+	if q.Status != "" && !slices.Contains(orderStatuses, q.Status) {
+		o.logger.DebugContext(ctx, "Passing unknown order status to the backend", "status", q.Status)
+	}
+
+	if from, _ := args["from"].(string); from != "" {
+		if q.From, err = parseDate(from, false); err != nil {
+			return mcp.NewToolCallError(fmt.Sprintf("Invalid from date: %s", err)), nil
+		}
+	}
+	if to, _ := args["to"].(string); to != "" {
+		if q.To, err = parseDate(to, true); err != nil {
+			return mcp.NewToolCallError(fmt.Sprintf("Invalid to date: %s", err)), nil
+		}
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return mcp.NewToolCallError("The from date must be before the to date"), nil
+	}
+
+	orders, err := o.backend.ListOrders(ctx, q)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch orders: %w", err)
+	}
+
+	o.logger.InfoContext(ctx, "Fetched orders", "count", len(orders))
+
+	if len(orders) == 0 {
+		text := "📦 No orders found"
+		if q.Offset > 0 {
+			text += fmt.Sprintf(" after the first %d", q.Offset)
+		}
+		return textResult(text + filterSummary(q)), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📦 Orders %d-%d%s:\n\n", q.Offset+1, q.Offset+len(orders), filterSummary(q))
+	for i, order := range orders {
+		fmt.Fprintf(&b, "%d. Order #%d - %s - %s - %d items, placed %s\n", q.Offset+i+1,
+			order.Id,
+			order.Status,
+			format.Format(order.Total),
+			itemCount(order),
+			formatTime(order.CreatedAt))
+	}
+	if len(orders) == q.Limit {
+		fmt.Fprintf(&b, "\nThere may be more orders; call list_orders with offset %d to see them.\n", q.Offset+len(orders))
+	}
+	b.WriteString("\nCall get_order_details with an order ID for items, tracking and the status timeline.")
+	b.WriteString(format.Note())
+
+	return mcp.CallToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: b.String(),
+			},
+		},
+		StructuredContent: map[string]any{"orders": orders},
+	}, nil
+}
+
+func (o *OrderToolset) handleGetOrderDetails(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	o.logger.InfoContext(ctx, "Getting order details", "args", args)
+
+	orderIDFloat, ok := args["order_id"].(float64)
+	if !ok {
+		return mcp.CallToolResult{}, fmt.Errorf("invalid order_id; %+v", args["order_id"])
+	}
+	orderID := int(orderIDFloat)
+
+	currency, _ := args["currency"].(string)
+	format, err := o.money.Formatter(currency)
+	if err != nil {
+		return mcp.NewToolCallError(err.Error()), nil
+	}
+
+	order, err := o.backend.GetOrder(ctx, orderID)
+	if errors.Is(err, backend.ErrNotFound) {
+		return mcp.NewToolCallError(fmt.Sprintf("Order %d was not found; call list_orders to see the user's orders", orderID)), nil
+	}
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch order %d: %w", orderID, err)
+	}
+
+	return mcp.CallToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: formatOrderDetail(*order, format) + format.Note(),
+			},
+		},
+		StructuredContent: order,
+	}, nil
+}
+
+func formatOrderDetail(order models.Order, format money.Formatter) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Order #%d** - %s\n", order.Id, order.Status)
+	fmt.Fprintf(&b, "Placed: %s\n", formatTime(order.CreatedAt))
+
+	fmt.Fprintf(&b, "\nItems (%d):\n", itemCount(order))
+	for _, item := range order.OrderItems {
+		fmt.Fprintf(&b, "- %s (product %d) - %s × %d = %s\n",
+			item.Product.Name,
+			item.Product.Id,
+			format.Format(item.Price),
+			item.Quantity,
+			format.Format(item.Subtotal))
+	}
+
+	b.WriteString("\n")
+	if order.Subtotal > 0 {
+		fmt.Fprintf(&b, "Subtotal: %s\n", format.Format(order.Subtotal))
+	}
+	if order.DiscountAmount > 0 {
+		discount := "Discount"
+		if len(order.CouponCodes) > 0 {
+			discount += " (" + strings.Join(order.CouponCodes, ", ") + ")"
+		}
+		fmt.Fprintf(&b, "🏷 %s: -%s\n", discount, format.Format(order.DiscountAmount))
+	} else if len(order.CouponCodes) > 0 {
+		fmt.Fprintf(&b, "🏷 Codes: %s\n", strings.Join(order.CouponCodes, ", "))
+	}
+	if order.ShippingCost > 0 {
+		fmt.Fprintf(&b, "Shipping: %s\n", format.Format(order.ShippingCost))
+	}
+	if order.TaxAmount > 0 {
+		fmt.Fprintf(&b, "Tax: %s\n", format.Format(order.TaxAmount))
+	}
+	fmt.Fprintf(&b, "💰 Total: %s\n", format.Charged(order.Total))
+
+	if len(order.StatusHistory) > 0 {
+		b.WriteString("\nStatus timeline:\n")
+		for _, event := range order.StatusHistory {
+			fmt.Fprintf(&b, "- %s: %s", formatTime(event.CreatedAt), event.Status)
+			if event.Note != "" {
+				fmt.Fprintf(&b, " - %s", event.Note)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\nShipping:\n")
+	b.WriteString(formatShipping(order))
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func formatShipping(order models.Order) string {
+	s := order.Shipping
+	if s == nil {
+		if order.Status == models.OrderCancelled {
+			return "- Not shipped; the order was cancelled\n"
+		}
+		return "- No shipping information yet\n"
+	}
+
+	var b strings.Builder
+	if s.Method != "" {
+		fmt.Fprintf(&b, "- Method: %s\n", s.Method)
+	}
+	if s.Carrier != "" || s.TrackingNumber != "" {
+		fmt.Fprintf(&b, "- Tracking: %s %s\n", s.Carrier, s.TrackingNumber)
+	}
+	if s.TrackingURL != "" {
+		fmt.Fprintf(&b, "- Track at: %s\n", s.TrackingURL)
+	}
+	if s.ShippedAt != nil {
+		fmt.Fprintf(&b, "- Shipped: %s\n", formatTime(*s.ShippedAt))
+	}
+	switch {
+	case s.DeliveredAt != nil:
+		fmt.Fprintf(&b, "- Delivered: %s\n", formatTime(*s.DeliveredAt))
+	case s.EstimatedDelivery != nil:
+		fmt.Fprintf(&b, "- Estimated delivery: %s\n", s.EstimatedDelivery.Format(time.DateOnly))
+	}
+	if a := s.Address; a != nil {
+		fmt.Fprintf(&b, "- Address: %s\n", formatAddress(*a))
+	}
+	if b.Len() == 0 {
+		return "- No shipping information yet\n"
+	}
+	return b.String()
+}
+
+func formatAddress(a models.Address) string {
+	parts := []string{a.Name, a.Line1, a.Line2, a.City, strings.TrimSpace(a.Region + " " + a.PostalCode), a.Country}
+	return strings.Join(slices.DeleteFunc(parts, func(p string) bool { return p == "" }), ", ")
+}
+
+func filterSummary(q backend.OrderQuery) string {
+	var filters []string
+	if q.Status != "" {
+		filters = append(filters, "status "+q.Status)
+	}
+	if !q.From.IsZero() {
+		filters = append(filters, "from "+q.From.Format(time.DateOnly))
+	}
+	if !q.To.IsZero() {
+		filters = append(filters, "until "+q.To.Add(-time.Nanosecond).Format(time.DateOnly))
+	}
+	if len(filters) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(filters, ", ") + ")"
+}
+
+func itemCount(order models.Order) int {
+	count := 0
+	for _, item := range order.OrderItems {
+		count += item.Quantity
+	}
+	return count
+}
+
+func formatTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04 MST")
+}
+
+// parseDate accepts a date or an RFC 3339 timestamp. The range end is
+// exclusive, so the end of a range is moved past the day of a plain date and
+// past the second of a timestamp, which is as precise as backends filter.
+func parseDate(s string, end bool) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		if end {
+			t = t.Truncate(time.Second).Add(time.Second)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a YYYY-MM-DD date", s)
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func textResult(text string) mcp.CallToolResult {
+	return mcp.CallToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package orders
+
+import (
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/tooltest"
+	"testing"
+)
+
+func TestListOrders(t *testing.T) {
+	reg, _ := newToolset(t, "list_orders", Options{})
+
+	result := tooltest.Call(t, reg, "list_orders", "")
+	tooltest.Contains(t, result,
+		"Orders 1-3:",
+		"1. Order #103 - cancelled",
+		"3. Order #101 - delivered",
+		"placed 2026-08-03 09:12 UTC")
+
+	result = tooltest.Call(t, reg, "list_orders", `{"status": "Shipped"}`)
+	tooltest.Contains(t, result, "Orders 1-1 (status shipped)", "Order #102 - shipped")
+
+	result = tooltest.Call(t, reg, "list_orders", `{"from": "2026-09-01", "to": "2026-10-05"}`)
+	tooltest.Contains(t, result, "Orders 1-2 (from 2026-09-01, until 2026-10-05)", "Order #103", "Order #102")
+
+	result = tooltest.Call(t, reg, "list_orders", `{"to": "2026-09-28T18:30:00Z"}`)
+	tooltest.Contains(t, result, "Orders 1-2 (until 2026-09-28)", "Order #102", "Order #101")
+
+	result = tooltest.Call(t, reg, "list_orders", `{"status": "refunded"}`)
+	tooltest.Contains(t, result, "No orders found (status refunded)")
+
+	result = tooltest.Call(t, reg, "list_orders", `{"limit": 1, "offset": 1}`)
+	tooltest.Contains(t, result, "Orders 2-2:", "call list_orders with offset 2")
+
+	result = tooltest.Call(t, reg, "list_orders", `{"from": "2026-10-05", "to": "2026-09-01"}`)
+	if !result.IsError {
+		t.Errorf("expected an inverted range to be refused, got %q", tooltest.Text(result))
+	}
+}
+
+func TestGetOrderDetails(t *testing.T) {
+	reg, _ := newToolset(t, "get_order_details", Options{})
+
+	result := tooltest.Call(t, reg, "get_order_details", `{"order_id": 102}`)
+	tooltest.Contains(t, result,
+		"**Order #102** - shipped",
+		"Status timeline:",
+		"- 2026-09-30 14:20 UTC: shipped - Handed to carrier",
+		"Shipping:")
+
+	result = tooltest.Call(t, reg, "get_order_details", `{"order_id": 103}`)
+	tooltest.Contains(t, result, "Not shipped; the order was cancelled")
+
+	result = tooltest.Call(t, reg, "get_order_details", `{"order_id": 999}`)
+	if !result.IsError {
+		t.Errorf("expected a tool error for an unknown order, got %q", tooltest.Text(result))
+	}
+}
@@ -2,66 +2,166 @@ package orders
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/client"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
 	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/promotions"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/cart"
 	"log/slog"
+	"strings"
 )
 
+type Options struct {
+	// Promotions holds the promotion codes applied locally to the cart. The
+	// store does not know them, so they are only reported with the order and
+	// cleared once it is placed.
+	Promotions *promotions.Engine
+	// Snapshots holds the cart snapshots; they are cleared once the cart has
+	// become an order.
+	Snapshots *cart.Snapshots
+	// Money formats amounts in the session currency.
+	Money *money.Display
+}
+
 type OrderToolset struct {
 	reg        *mcp.Registry
 	logger     *slog.Logger
-	restClient *client.RestClient
+	backend    backend.Backend
+	validator  *cart.Validator
+	promotions *promotions.Engine
+	snapshots  *cart.Snapshots
+	money      *money.Display
 }
 
 func (o *OrderToolset) registerOrderTools() {
 	o.reg.Register(mcp.Tool{
 		Name:        "place_order",
-		Description: "Place a new order with the items in the shopping cart (requires authentication)",
+		Title:       "Place Order",
+		Description: "Place a new order with the items in the shopping cart. The cart is validated first and the order is refused while it has problems (requires authentication)",
 		InputSchema: mcp.InputSchema{
-			Type:       "object",
-			Properties: map[string]mcp.Property{},
-			Required:   []string{},
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"accept_warnings": {
+					Type:        "boolean",
+					Description: "Set to true once the user has confirmed the warnings reported by validate_cart or place_order, such as price changes or local promotions the store will not apply",
+				},
+			},
+			Required: []string{},
 		},
+		Annotations: mcp.Mutating(false, false),
 	}, o.handlePlaceOrder)
 }
 
-func (o *OrderToolset) handlePlaceOrder(_ context.Context, _ map[string]any) (mcp.CallToolResult, error) {
+func (o *OrderToolset) handlePlaceOrder(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	acceptWarnings, _ := args["accept_warnings"].(bool)
+
+	current, err := o.backend.GetCart(ctx)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch cart: %w", err)
+	}
+
+	report, err := o.validator.Validate(ctx, current)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to validate cart: %w", err)
+	}
+
+	format, _ := o.money.Formatter("")
+
+	// The store does not know local promotions, so the order is charged
+	// without them; that needs the user's confirmation like a price change.
+	local := o.promotions.Discounts(current)
+	for _, d := range local {
+		report.Problems = append(report.Problems, cart.Problem{
+			Code:     cart.ProblemLocalPromotion,
+			Severity: cart.SeverityWarning,
+			Message:  fmt.Sprintf("%s (-%s) is a local promotion the store does not know; the order will be charged without it", d.Code, format.Format(d.Amount)),
+			Fix:      fmt.Sprintf("Tell the user the discount will not be applied and get their confirmation, or remove it with remove_coupon (code %s)", d.Code),
+		})
+		report.Valid = false
+	}
+
+	if blocking := report.Blocking(acceptWarnings); len(blocking) > 0 {
+		o.logger.WarnContext(ctx, "Order blocked by cart validation", "problems", len(blocking))
+
+		text := "Order not placed. " + cart.FormatReport(report)
+		if len(report.Blocking(true)) == 0 {
+			text += "\n\nOnly warnings remain. After the user confirms them, call place_order again with accept_warnings set to true."
+		}
+		return mcp.CallToolResult{
+			Content: []mcp.Content{
+				{
+					Type: "text",
+					Text: text,
+				},
+			},
+			StructuredContent: report,
+			IsError:           true,
+		}, nil
+	}
+
+	codes := make([]string, 0, len(current.Discounts))
+	for _, d := range current.Discounts {
+		codes = append(codes, d.Code)
+	}
 
-	response, err := o.restClient.WithToken().Post("/orders", nil)
+	order, err := o.backend.PlaceOrder(ctx, backend.PlaceOrderRequest{CouponCodes: codes})
 	if err != nil {
-		o.logger.Error("Failed to place order", "error", err)
-		return mcp.NewToolCallError("Failed to place order"), nil
+		o.logger.ErrorContext(ctx, "Failed to place order", "error", err)
+		return mcp.CallToolResult{}, fmt.Errorf("failed to place order: %w", err)
 	}
 
-	var orderRes OrderResponse
-	if err := json.Unmarshal(response, &orderRes); err != nil {
-		o.logger.Error("Failed to unmarshal order response", "error", err)
-		return mcp.NewToolCallError("Failed to parse order response"), nil
+	o.promotions.Clear()
+	o.snapshots.Clear()
+
+	text := fmt.Sprintf("Order placed successfully! Order ID: %d, Total Amount: %s",
+		order.Id,
+		format.Charged(order.Total))
+	if len(codes) > 0 {
+		text += fmt.Sprintf("\nPromotion codes sent with the order: %s", strings.Join(codes, ", "))
+	}
+	for _, d := range local {
+		text += fmt.Sprintf("\n🏷 %s (-%s) was a local promotion and was not applied by the store, as confirmed.", d.Code, format.Format(d.Amount))
 	}
+	text += format.Note()
 
 	return mcp.CallToolResult{
 		Content: []mcp.Content{
 			{
 				Type: "text",
-				Text: fmt.Sprintf("Order placed successfully! Order ID: %d, Total Amount: $%.2f",
-					orderRes.Data.Id,
-					orderRes.Data.Total),
+				Text: text,
 			},
 		},
 		IsError: false,
 	}, nil
 }
 
-func NewOrderToolset(reg *mcp.Registry, restClient *client.RestClient, logger *slog.Logger) *OrderToolset {
+func NewOrderToolset(reg *mcp.Registry, store backend.Backend, opts Options, logger *slog.Logger) *OrderToolset {
+	engine := opts.Promotions
+	if engine == nil {
+		engine, _ = promotions.NewEngine(nil, money.USD)
+	}
+	snapshots := opts.Snapshots
+	if snapshots == nil {
+		snapshots = cart.NewSnapshots(cart.DefaultSnapshotLimit, engine, logger)
+	}
+	display := opts.Money
+	if display == nil {
+		display, _ = money.NewDisplay(money.USD, nil)
+	}
+
 	ot := &OrderToolset{
 		reg:        reg,
-		restClient: restClient,
+		backend:    store,
+		validator:  cart.NewValidator(store, display.Store()),
+		promotions: engine,
+		snapshots:  snapshots,
+		money:      display,
 		logger:     logger,
 	}
 
 	ot.registerOrderTools()
+	ot.registerHistoryTools()
 
 	return ot
 }
@@ -0,0 +1,71 @@
+package orders
+
+import (
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/promotions"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/cart"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/tooltest"
+	"strings"
+	"testing"
+)
+
+// newToolset registers the order tools, and the cart tools they work on,
+// against the cassette's backend, starting from an empty cart.
+func newToolset(t *testing.T, cassette string, opts Options) (*mcp.Registry, backend.Backend) {
+	t.Helper()
+
+	reg, store := tooltest.Setup(t, cassette, tooltest.Options{})
+	cart.NewCartToolset(reg, store, cart.Options{Promotions: opts.Promotions, Snapshots: opts.Snapshots}, tooltest.Logger())
+	NewOrderToolset(reg, store, opts, tooltest.Logger())
+	return reg, store
+}
+
+func TestPlaceOrder(t *testing.T) {
+	reg, _ := newToolset(t, "place_order", Options{})
+
+	result := tooltest.Call(t, reg, "place_order", "")
+	if !result.IsError {
+		t.Fatalf("expected an empty cart to be refused, got %q", tooltest.Text(result))
+	}
+	tooltest.Contains(t, result, "Order not placed", "[empty_cart]")
+
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 2, "quantity": 4}`)
+	tooltest.Call(t, reg, "apply_coupon", `{"code": "FIVEOFF"}`)
+
+	result = tooltest.Call(t, reg, "place_order", "")
+	tooltest.Contains(t, result, "Order placed successfully! Order ID: 104, Total Amount: $46.60", "Promotion codes sent with the order: FIVEOFF")
+
+	result = tooltest.Call(t, reg, "view_cart", "")
+	tooltest.Contains(t, result, "Your cart is empty")
+}
+
+func TestPlaceOrderKeepsLocalPromotionsFromTheStore(t *testing.T) {
+	engine, err := promotions.Load("../../../fixtures/promotions.json", money.USD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg, _ := newToolset(t, "place_order_local_promotion", Options{Promotions: engine})
+
+	tooltest.Call(t, reg, "add_to_cart", `{"product_id": 4}`)
+	tooltest.Call(t, reg, "apply_coupon", `{"code": "BREWDAY"}`)
+
+	result := tooltest.Call(t, reg, "place_order", "")
+	if !result.IsError {
+		t.Fatalf("expected the order to wait for confirmation of the local promotion, got %q", tooltest.Text(result))
+	}
+	tooltest.Contains(t, result, "Order not placed", "[local_promotion] BREWDAY (-$5.80)", "accept_warnings set to true")
+
+	result = tooltest.Call(t, reg, "place_order", `{"accept_warnings": true}`)
+	tooltest.Contains(t, result,
+		"Order placed successfully!",
+		"Total Amount: $29.00",
+		"BREWDAY (-$5.80) was a local promotion and was not applied by the store")
+	if strings.Contains(tooltest.Text(result), "Promotion codes sent with the order") {
+		t.Errorf("no store codes should be reported, got %q", tooltest.Text(result))
+	}
+	if codes := engine.Applied(); len(codes) != 0 {
+		t.Errorf("expected local promotions to be cleared, got %v", codes)
+	}
+}
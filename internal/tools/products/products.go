@@ -2,23 +2,33 @@ package products
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/client"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
 	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
 	"log/slog"
+	"strconv"
+	"strings"
 )
 
+type Options struct {
+	// Money formats prices in the session or requested currency.
+	Money *money.Display
+}
+
 type ProductToolset struct {
-	reg        *mcp.Registry
-	logger     *slog.Logger
-	restClient *client.RestClient
+	reg     *mcp.Registry
+	logger  *slog.Logger
+	catalog backend.Catalog
+	money   *money.Display
 }
 
 func (r *ProductToolset) registerProductTools() {
 	r.reg.Register(mcp.Tool{
 		Name:        "list_products",
+		Title:       "List Products",
 		Description: "List all available products from the store",
 		InputSchema: mcp.InputSchema{
 			Type: "object",
@@ -31,13 +41,19 @@ func (r *ProductToolset) registerProductTools() {
 					Type:        "number",
 					Description: "Number of products to skip (default: 0)",
 				},
+				"currency": {
+					Type:        "string",
+					Description: "Currency to show prices in, e.g. EUR or GBP (default: the session currency set with set_currency)",
+				},
 			},
 			Required: []string{},
 		},
+		Annotations: mcp.ReadOnly(),
 	}, r.handleListProducts)
 
 	r.reg.Register(mcp.Tool{
 		Name:        "search_products",
+		Title:       "Search Products",
 		Description: "Search for products using a query string",
 		InputSchema: mcp.InputSchema{
 			Type: "object",
@@ -56,23 +72,29 @@ func (r *ProductToolset) registerProductTools() {
 				},
 				"min_price": {
 					Type:        "number",
-					Description: "Minimum price to filter products",
+					Description: "Minimum price to filter products, in the store currency",
 				},
 				"max_price": {
 					Type:        "number",
-					Description: "Maximum price to filter products",
+					Description: "Maximum price to filter products, in the store currency",
 				},
 				"category_id": {
 					Type:        "string",
 					Description: "The category ID to filter products",
 				},
+				"currency": {
+					Type:        "string",
+					Description: "Currency to show prices in, e.g. EUR or GBP (default: the session currency set with set_currency)",
+				},
 			},
 			Required: []string{},
 		},
+		Annotations: mcp.ReadOnly(),
 	}, r.searchProducts)
 
 	r.reg.Register(mcp.Tool{
 		Name:        "get_product_details",
+		Title:       "Get Product Details",
 		Description: "Get detailed information about a specific product by its ID",
 		InputSchema: mcp.InputSchema{
 			Type: "object",
@@ -81,45 +103,62 @@ func (r *ProductToolset) registerProductTools() {
 					Type:        "string",
 					Description: "The unique identifier of the product",
 				},
+				"currency": {
+					Type:        "string",
+					Description: "Currency to show prices in, e.g. EUR or GBP (default: the session currency set with set_currency)",
+				},
 			},
 			Required: []string{"product_id"},
 		},
+		Annotations: mcp.ReadOnly(),
 	}, r.getProductDetails)
 
 }
 
-func (r *ProductToolset) getProductDetails(_ context.Context, args map[string]any) (mcp.CallToolResult, error) {
-	productID, ok := args["product_id"].(string)
-	if !ok || productID == "" {
+func (r *ProductToolset) getProductDetails(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	productIDStr, ok := args["product_id"].(string)
+	if !ok || productIDStr == "" {
 		return mcp.CallToolResult{}, errors.New("product_id is required and must be a string of numbers. e.g 123")
 	}
 
-	response, err := r.restClient.Get(fmt.Sprintf("/products/%s", productID), nil)
+	productID, err := strconv.Atoi(productIDStr)
 	if err != nil {
-		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch product details: %w", err)
+		return mcp.CallToolResult{}, errors.New("product_id is required and must be a string of numbers. e.g 123")
 	}
 
-	var product ProductDetailResponse
-	if err := json.Unmarshal(response, &product); err != nil {
-		return mcp.CallToolResult{}, fmt.Errorf("failed to parse product detail data: %w", err)
+	currency, _ := args["currency"].(string)
+	format, err := r.money.Formatter(currency)
+	if err != nil {
+		return mcp.NewToolCallError(err.Error()), nil
+	}
+
+	product, err := r.catalog.GetProduct(ctx, productID)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch product details: %w", err)
 	}
 
 	return mcp.CallToolResult{
 		Content: []mcp.Content{
 			{
 				Type: "text",
-				Text: formatProductDetail(product.Data),
+				Text: strings.TrimSuffix(formatProductDetail(*product, format), "\n") + format.Note(),
 			},
 		},
 	}, nil
 }
 
-func (r *ProductToolset) searchProducts(_ context.Context, args map[string]any) (mcp.CallToolResult, error) {
+func (r *ProductToolset) searchProducts(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
 
 	q, _ := args["q"].(string)
 	minPrice, _ := args["min_price"].(float64)
 	maxPrice, _ := args["max_price"].(float64)
 	categoryId, _ := args["category_id"].(string)
+	currency, _ := args["currency"].(string)
+
+	format, err := r.money.Formatter(currency)
+	if err != nil {
+		return mcp.NewToolCallError(err.Error()), nil
+	}
 
 	limit := 20
 	offset := 0
@@ -132,52 +171,43 @@ func (r *ProductToolset) searchProducts(_ context.Context, args map[string]any)
 		offset = int(o)
 	}
 
-	params := map[string]string{
-		"limit":  fmt.Sprintf("%d", limit),
-		"offset": fmt.Sprintf("%d", offset),
-		"q":      q,
-	}
-
-	if minPrice > 0 {
-		params["min_price"] = fmt.Sprintf("%.2f", minPrice)
-	}
-
-	if maxPrice > 0 {
-		params["max_price"] = fmt.Sprintf("%.2f", maxPrice)
-	}
-
-	if categoryId != "" {
-		params["category"] = categoryId
-	}
-
-	response, err := r.restClient.Get("/search", params)
+	products, err := r.catalog.SearchProducts(ctx, backend.SearchQuery{
+		Query:      q,
+		Limit:      limit,
+		Offset:     offset,
+		MinPrice:   minPrice,
+		MaxPrice:   maxPrice,
+		CategoryID: categoryId,
+	})
 	if err != nil {
 		return mcp.CallToolResult{}, fmt.Errorf("failed to search products: %w", err)
 	}
 
-	r.logger.Info("Fetched products", "response", string(response))
-	var products ProductResponse
-	if err := json.Unmarshal(response, &products); err != nil {
-		return mcp.CallToolResult{}, fmt.Errorf("failed to parse products: %w", err)
-	}
+	r.logger.InfoContext(ctx, "Fetched products", "count", len(products))
 
-	resultText := fmt.Sprintf("Found %d products:\n\n", len(products.Data))
-	for i, product := range products.Data {
-		resultText += fmt.Sprintf("%d. %s\n", i+1, formatProduct(product))
+	resultText := fmt.Sprintf("Found %d products:\n\n", len(products))
+	for i, product := range products {
+		resultText += fmt.Sprintf("%d. %s\n", i+1, formatProduct(product, format))
 	}
 
 	return mcp.CallToolResult{
 		Content: []mcp.Content{
 			{
 				Type: "text",
-				Text: resultText,
+				Text: strings.TrimSuffix(resultText, "\n") + format.Note(),
 			},
 		},
 	}, nil
 
 }
 
-func (r *ProductToolset) handleListProducts(_ context.Context, args map[string]any) (mcp.CallToolResult, error) {
+func (r *ProductToolset) handleListProducts(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+
+	currency, _ := args["currency"].(string)
+	format, err := r.money.Formatter(currency)
+	if err != nil {
+		return mcp.NewToolCallError(err.Error()), nil
+	}
 
 	limit := 20
 	offset := 0
@@ -190,65 +220,64 @@ func (r *ProductToolset) handleListProducts(_ context.Context, args map[string]a
 		offset = int(o)
 	}
 
-	params := map[string]string{
-		"limit":  fmt.Sprintf("%d", limit),
-		"offset": fmt.Sprintf("%d", offset),
-	}
-
-	response, err := r.restClient.Get("/products", params)
+	products, err := r.catalog.ListProducts(ctx, backend.ProductQuery{
+		Limit:  limit,
+		Offset: offset,
+	})
 	if err != nil {
 		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch products: %w", err)
 	}
 
-	r.logger.Info("Fetched products", "response", string(response))
+	r.logger.InfoContext(ctx, "Fetched products", "count", len(products))
 
-	var products ProductResponse
-	if err := json.Unmarshal(response, &products); err != nil {
-		return mcp.CallToolResult{}, fmt.Errorf("failed to parse products: %w", err)
-	}
-
-	resultText := fmt.Sprintf("Found %d products:\n\n", len(products.Data))
-	for i, product := range products.Data {
-		resultText += fmt.Sprintf("%d. %s\n", i+1, formatProduct(product))
+	resultText := fmt.Sprintf("Found %d products:\n\n", len(products))
+	for i, product := range products {
+		resultText += fmt.Sprintf("%d. %s\n", i+1, formatProduct(product, format))
 	}
 
 	return mcp.CallToolResult{
 		Content: []mcp.Content{
 			{
 				Type: "text",
-				Text: resultText,
+				Text: strings.TrimSuffix(resultText, "\n") + format.Note(),
 			},
 		},
 	}, nil
 }
 
-func formatProduct(product Product) string {
+func formatProduct(product models.Product, format money.Formatter) string {
 	name := product.Name
-	price := product.Price
+	price := format.Format(product.Price)
 	id := product.Id
 
-	return fmt.Sprintf("**%s** (ID: %d) - $%.2f", name, id, price)
+	return fmt.Sprintf("**%s** (ID: %d) - %s", name, id, price)
 }
 
-func formatProductDetail(product Product) string {
+func formatProductDetail(product models.Product, format money.Formatter) string {
 	return fmt.Sprintf(`**Product Details**
 
 ID: %d
 Name: %s
 Category: %s
-Price: $%.2f
+Price: %s
 Stock: %d units
 
 Description:
 %s
-`, product.Id, product.Name, product.Category.Name, product.Price, product.Stock, product.Description)
+`, product.Id, product.Name, product.Category.Name, format.Format(product.Price), product.Stock, product.Description)
 }
 
-func NewProductToolSet(reg *mcp.Registry, restClient *client.RestClient, logger *slog.Logger) *ProductToolset {
+func NewProductToolSet(reg *mcp.Registry, catalog backend.Catalog, opts Options, logger *slog.Logger) *ProductToolset {
+	display := opts.Money
+	if display == nil {
+		display, _ = money.NewDisplay(money.USD, nil)
+	}
+
 	pt := &ProductToolset{
-		reg:        reg,
-		restClient: restClient,
-		logger:     logger,
+		reg:     reg,
+		catalog: catalog,
+		money:   display,
+		logger:  logger,
 	}
 
 	pt.registerProductTools()
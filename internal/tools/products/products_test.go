@@ -0,0 +1,64 @@
+package products
+
+import (
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/tooltest"
+	"testing"
+)
+
+func newToolset(t *testing.T, cassette string) *mcp.Registry {
+	t.Helper()
+
+	rates, err := money.NewRates(money.USD, "2026-10-01", map[string]float64{"EUR": 0.92})
+	if err != nil {
+		t.Fatal(err)
+	}
+	display, err := money.NewDisplay(money.USD, rates)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg, store := tooltest.Setup(t, cassette, tooltest.Options{KeepCart: true})
+	NewProductToolSet(reg, store, Options{Money: display}, tooltest.Logger())
+	return reg
+}
+
+func TestListProducts(t *testing.T) {
+	reg := newToolset(t, "list_products")
+
+	result := tooltest.Call(t, reg, "list_products", `{"limit": 3}`)
+	tooltest.Contains(t, result,
+		"Found 3 products",
+		"**Ethiopia Yirgacheffe Whole Beans 1kg** (ID: 1) - $24.50",
+		"**Espresso Blend Whole Beans 1kg** (ID: 3) - $21.00")
+
+	result = tooltest.Call(t, reg, "list_products", `{"limit": 2, "offset": 3, "currency": "EUR"}`)
+	tooltest.Contains(t, result, "Found 2 products", "**Pour-Over Dripper** (ID: 4) - ≈€26.68", "Estimated in EUR")
+}
+
+func TestSearchProducts(t *testing.T) {
+	reg := newToolset(t, "search_products")
+
+	result := tooltest.Call(t, reg, "search_products", `{"q": "beans"}`)
+	tooltest.Contains(t, result, "Found 2 products", "Ethiopia Yirgacheffe", "Espresso Blend")
+
+	result = tooltest.Call(t, reg, "search_products", `{"category_id": "2", "max_price": 40}`)
+	tooltest.Contains(t, result, "Found 1 products", "**Pour-Over Dripper** (ID: 4) - $29.00")
+}
+
+func TestGetProductDetails(t *testing.T) {
+	reg := newToolset(t, "get_product_details")
+
+	result := tooltest.Call(t, reg, "get_product_details", `{"product_id": "6"}`)
+	tooltest.Contains(t, result, "Name: Burr Grinder", "Category: Brewing", "Price: $129.00", "Stock: 3 units")
+
+	if _, err := tooltest.Try(t, reg, "get_product_details", `{"product_id": "999"}`); err == nil {
+		t.Error("expected an error for an unknown product")
+	}
+
+	result = tooltest.Call(t, reg, "get_product_details", `{"product_id": "6", "currency": "XYZ"}`)
+	if !result.IsError {
+		t.Errorf("expected a tool error for an unknown currency, got %q", tooltest.Text(result))
+	}
+}
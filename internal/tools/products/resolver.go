@@ -0,0 +1,208 @@
+package products
+
+import (
+	"context"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const maxCandidates = 5
+
+// Ref names a product the way a user or agent refers to it. Exactly one of
+// the fields is expected to be set.
+type Ref struct {
+	ProductID int
+	SKU       string
+	Name      string
+}
+
+func (r Ref) String() string {
+	switch {
+	case r.ProductID != 0:
+		return fmt.Sprintf("product %d", r.ProductID)
+	case r.SKU != "":
+		return "SKU " + r.SKU
+	default:
+		return fmt.Sprintf("%q", r.Name)
+	}
+}
+
+// AmbiguousError is returned when a name does not identify a single product.
+// Partial is set when none of the candidates matched every word of the name.
+type AmbiguousError struct {
+	Name       string
+	Candidates []models.Product
+	Partial    bool
+}
+
+func (e *AmbiguousError) Error() string {
+	if e.Partial {
+		return fmt.Sprintf("no product named %q; %d similar products found", e.Name, len(e.Candidates))
+	}
+	return fmt.Sprintf("%q matches %d products", e.Name, len(e.Candidates))
+}
+
+// Prompt lists the candidates so the user can pick one.
+func (e *AmbiguousError) Prompt(format money.Formatter) string {
+	var b strings.Builder
+	if e.Partial {
+		fmt.Fprintf(&b, "No product is named %q. Similar products:\n\n", e.Name)
+	} else {
+		fmt.Fprintf(&b, "%q matches several products:\n\n", e.Name)
+	}
+	for i, p := range e.Candidates {
+		fmt.Fprintf(&b, "%d. **%s** (ID: %d, SKU: %s) - %s\n", i+1, p.Name, p.Id, p.Sku, format.Format(p.Price))
+	}
+	b.WriteString("\nAsk the user which product they mean, then retry with its product_id.")
+	b.WriteString(format.Note())
+	return b.String()
+}
+
+type Resolver struct {
+	catalog backend.Catalog
+}
+
+func (r *Resolver) Resolve(ctx context.Context, ref Ref) (*models.Product, error) {
+	switch {
+	case ref.ProductID != 0:
+		return r.catalog.GetProduct(ctx, ref.ProductID)
+	case strings.TrimSpace(ref.SKU) != "":
+		return r.catalog.GetProductBySKU(ctx, strings.TrimSpace(ref.SKU))
+	case strings.TrimSpace(ref.Name) != "":
+		return r.resolveName(ctx, ref.Name)
+	default:
+		return nil, fmt.Errorf("%w: a product ID, SKU or name is required", backend.ErrInvalid)
+	}
+}
+
+// resolveName only returns a product when it is the single one whose name
+// contains every word of the query, or whose name equals it. Anything less
+// certain is reported as an AmbiguousError for the user to settle.
+func (r *Resolver) resolveName(ctx context.Context, name string) (*models.Product, error) {
+	query := tokenize(name)
+	if len(query) == 0 {
+		return nil, fmt.Errorf("%w: product name is empty", backend.ErrInvalid)
+	}
+
+	found, err := r.search(ctx, name, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var exact, full, partial []models.Product
+	for _, p := range found {
+		tokens := tokenize(p.Name)
+		matched := matchedTokens(query, tokens)
+		switch {
+		case slices.Equal(tokens, query):
+			exact = append(exact, p)
+		case matched == len(query):
+			full = append(full, p)
+		case matched*2 >= len(query):
+			partial = append(partial, p)
+		}
+	}
+
+	switch {
+	case len(exact) == 1:
+		return &exact[0], nil
+	case len(exact) > 1:
+		return nil, &AmbiguousError{Name: name, Candidates: limit(exact)}
+	case len(full) == 1:
+		return &full[0], nil
+	case len(full) > 1:
+		return nil, &AmbiguousError{Name: name, Candidates: limit(full)}
+	case len(partial) > 0:
+		return nil, &AmbiguousError{Name: name, Candidates: limit(partial), Partial: true}
+	default:
+		return nil, fmt.Errorf("%w: no product named %q", backend.ErrNotFound, name)
+	}
+}
+
+// search asks the catalog for the whole name first and falls back to its
+// individual words, since the backend search is a plain substring match.
+func (r *Resolver) search(ctx context.Context, name string, query []string) ([]models.Product, error) {
+	products, err := r.catalog.SearchProducts(ctx, backend.SearchQuery{Query: name, Limit: 50})
+	if err != nil || len(products) > 0 {
+		return products, err
+	}
+
+	seen := make(map[int]bool)
+	for _, token := range query {
+		if len(token) < 3 {
+			continue
+		}
+		matches, err := r.catalog.SearchProducts(ctx, backend.SearchQuery{Query: token, Limit: 50})
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range matches {
+			if !seen[p.Id] {
+				seen[p.Id] = true
+				products = append(products, p)
+			}
+		}
+	}
+	return products, nil
+}
+
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func matchedTokens(query, tokens []string) int {
+	matched := 0
+	for _, q := range query {
+		if slices.ContainsFunc(tokens, func(t string) bool { return similar(q, t) }) {
+			matched++
+		}
+	}
+	return matched
+}
+
+// similar accepts prefixes ("yirga" for "yirgacheffe") and, for longer words,
+// a single typo.
+func similar(q, t string) bool {
+	if strings.HasPrefix(t, q) {
+		return true
+	}
+	return len(q) >= 5 && editDistance(q, t) <= 1
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func limit(products []models.Product) []models.Product {
+	if len(products) > maxCandidates {
+		return products[:maxCandidates]
+	}
+	return products
+}
+
+func NewResolver(catalog backend.Catalog) *Resolver {
+	return &Resolver{catalog: catalog}
+}
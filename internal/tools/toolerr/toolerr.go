@@ -0,0 +1,97 @@
+package toolerr
+
+import (
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/client"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"net/http"
+	"strings"
+)
+
+func Map(err error) mcp.CallToolResult {
+	var circuitErr *client.CircuitOpenError
+	var rateErr *client.RateLimitError
+	var apiErr *client.APIError
+	switch {
+	case errors.As(err, &circuitErr):
+		return mcp.NewToolCallError(circuitOpenMessage(circuitErr))
+	case errors.As(err, &rateErr):
+		return mcp.NewToolCallError(fmt.Sprintf("Rate limit reached: too many %s requests in a short time. Wait at least %s before calling this tool again, and avoid repeating identical calls.", rateErr.Group, rateErr.RetryIn))
+	case errors.As(err, &apiErr):
+		return mcp.NewToolCallError(apiErrorMessage(apiErr))
+	case errors.Is(err, backend.ErrNotFound):
+		return mcp.NewToolCallError(fmt.Sprintf("Not found: %s\n\nUse search_products or list_products to find a valid product ID, or view_cart to see the cart.", err.Error()))
+	case errors.Is(err, backend.ErrConflict):
+		return mcp.NewToolCallError(fmt.Sprintf("Conflict: %s\n\nCall view_cart and get_product_details to check the current state before trying again.", err.Error()))
+	case errors.Is(err, backend.ErrInvalid), errors.Is(err, backend.ErrRejected):
+		return mcp.NewToolCallError(fmt.Sprintf("Invalid request: %s\n\nCorrect the arguments before retrying.", err.Error()))
+	case errors.Is(err, backend.ErrUnsupported):
+		return mcp.NewToolCallError(fmt.Sprintf("Not supported: %s", err.Error()))
+	default:
+		return mcp.NewToolCallError(fmt.Sprintf("Error: %s", err.Error()))
+	}
+}
+
+func circuitOpenMessage(err *client.CircuitOpenError) string {
+	wait := "shortly"
+	if err.RetryIn > 0 {
+		wait = "in " + err.RetryIn.String()
+	}
+	return fmt.Sprintf("Store temporarily unavailable: the %s service is not responding. Tell the user and try again %s instead of retrying right away.", err.Group, wait)
+}
+
+func apiErrorMessage(err *client.APIError) string {
+	var title, hint string
+	switch {
+	case err.StatusCode == http.StatusUnauthorized:
+		title = "Authentication failed"
+		hint = "The configured AUTH_TOKEN is missing, invalid or expired. Ask the user to sign in again or have the operator update the token; retrying will not help."
+	case err.StatusCode == http.StatusForbidden:
+		title = "Not allowed"
+		hint = "The signed-in account is not permitted to do this. Do not retry."
+	case err.StatusCode == http.StatusNotFound:
+		title = "Not found"
+		hint = notFoundHint(err.Route)
+	case err.StatusCode == http.StatusConflict:
+		title = "Conflict"
+		hint = "The request clashes with the current state, for example the item is out of stock or the cart changed. Call view_cart and get_product_details to check the current state before trying again."
+	case err.StatusCode == http.StatusBadRequest, err.StatusCode == http.StatusUnprocessableEntity:
+		title = "Invalid request"
+		hint = "The store rejected the arguments. Check IDs and quantities against the catalog and correct them before retrying."
+	case err.StatusCode == http.StatusTooManyRequests:
+		title = "Too many requests"
+		hint = "The store is rate limiting this account. Wait a little before making more calls."
+	case err.StatusCode >= 500:
+		title = "Store error"
+		hint = "The store failed to process the request. This is not caused by the arguments; tell the user and try again later."
+	default:
+		title = "Request failed"
+		hint = "The store returned an unexpected response."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s %s returned %d)", title, err.Method, err.Route, err.StatusCode)
+	if reason := err.Reason(); reason != "" {
+		fmt.Fprintf(&b, ": %s", reason)
+	}
+	fmt.Fprintf(&b, "\n\n%s", hint)
+	if err.RequestID != "" {
+		fmt.Fprintf(&b, "\n\nBackend request ID: %s", err.RequestID)
+	}
+	return b.String()
+}
+
+func notFoundHint(route string) string {
+	switch client.RouteGroup(route) {
+	case "catalog":
+		return "The product or category does not exist. Use search_products or list_products to find a valid ID."
+	case "cart":
+		return "The cart or cart item does not exist. Call view_cart to see the current cart."
+	case "orders":
+		return "The order does not exist for this account. Check the order ID."
+	default:
+		return "The requested resource does not exist."
+	}
+}
@@ -0,0 +1,456 @@
+// Package fakeapi is a stand-in for the Cartopher REST API, serving the
+// routes the tools use from the in-memory backend. tooltest records cassettes
+// against it, so they can be recorded again without a live API.
+package fakeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend/memory"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/promotions"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Wishlist modes select how the API answers the wishlist routes.
+const (
+	// WishlistAPI serves a wishlist.
+	WishlistAPI = ""
+	// WishlistUnsupported answers 501 Not Implemented.
+	WishlistUnsupported = "unsupported"
+	// WishlistMissing has no wishlist routes, so they answer 404 like any
+	// unknown route.
+	WishlistMissing = "missing"
+)
+
+type Options struct {
+	// Fixture is the store data, such as fixtures/store.json.
+	Fixture  string
+	Wishlist string
+	// NoPromotions answers the coupon and promotion routes with 501.
+	NoPromotions bool
+}
+
+type server struct {
+	store      *memory.Backend
+	promos     []models.Promotion
+	engine     *promotions.Engine
+	noWishlist bool
+	noPromos   bool
+
+	mu       sync.Mutex
+	wishlist []models.WishlistItem
+	nextWish int
+	orderAdj map[int]float64
+	reqs     atomic.Int64
+}
+
+// New returns a fresh API over the fixture. The store knows the WELCOME10 and
+// FIVEOFF coupons.
+func New(opts Options) (http.Handler, error) {
+	store, err := memory.Load(opts.Fixture)
+	if err != nil {
+		return nil, err
+	}
+	promos := []models.Promotion{
+		{Code: "WELCOME10", Description: "10% off your first order", Type: models.PromotionPercentage, Value: 10},
+		{Code: "FIVEOFF", Description: "$5 off orders over $40", Type: models.PromotionFixedAmount, Value: 5, MinSubtotal: 40},
+	}
+	engine, err := promotions.NewEngine(promos, "USD")
+	if err != nil {
+		return nil, err
+	}
+	s := &server{
+		store:      store,
+		promos:     promos,
+		engine:     engine,
+		noWishlist: opts.Wishlist == WishlistUnsupported,
+		noPromos:   opts.NoPromotions,
+		orderAdj:   map[int]float64{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /products", s.listProducts)
+	mux.HandleFunc("GET /search", s.search)
+	mux.HandleFunc("GET /products/{id}", s.getProduct)
+	mux.HandleFunc("GET /cart", s.auth(s.getCart))
+	mux.HandleFunc("POST /cart/items", s.auth(s.addItem))
+	mux.HandleFunc("PUT /cart/items/{id}", s.auth(s.updateItem))
+	mux.HandleFunc("DELETE /cart/items/{id}", s.auth(s.removeItem))
+	mux.HandleFunc("DELETE /cart", s.auth(s.clearCart))
+	mux.HandleFunc("POST /cart/coupons", s.auth(s.applyCoupon))
+	mux.HandleFunc("DELETE /cart/coupons/{code}", s.auth(s.removeCoupon))
+	mux.HandleFunc("GET /promotions", s.auth(s.listPromotions))
+	mux.HandleFunc("POST /orders", s.auth(s.placeOrder))
+	mux.HandleFunc("GET /orders", s.auth(s.listOrders))
+	mux.HandleFunc("GET /orders/{id}", s.auth(s.getOrder))
+	if opts.Wishlist != WishlistMissing {
+		mux.HandleFunc("GET /wishlist", s.auth(s.getWishlist))
+		mux.HandleFunc("POST /wishlist/items", s.auth(s.addWish))
+		mux.HandleFunc("DELETE /wishlist/items/{id}", s.auth(s.removeWish))
+	}
+
+	return s.requestID(mux), nil
+}
+
+func (s *server) requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" {
+			id = fmt.Sprintf("req-%04d", s.reqs.Add(1))
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *server) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			fail(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func ok(w http.ResponseWriter, status int, message string, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"success": true, "message": message, "data": data})
+}
+
+func fail(w http.ResponseWriter, status int, message, detail string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"success": false, "message": message, "error": detail})
+}
+
+func failErr(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, backend.ErrNotFound):
+		fail(w, http.StatusNotFound, "not found", err.Error())
+	case errors.Is(err, backend.ErrInvalid):
+		fail(w, http.StatusUnprocessableEntity, "invalid request", err.Error())
+	case errors.Is(err, backend.ErrConflict):
+		fail(w, http.StatusConflict, "conflict", err.Error())
+	default:
+		fail(w, http.StatusInternalServerError, "internal error", err.Error())
+	}
+}
+
+func intQuery(r *http.Request, name string, def int) int {
+	if v, err := strconv.Atoi(r.URL.Query().Get(name)); err == nil {
+		return v
+	}
+	return def
+}
+
+func (s *server) listProducts(w http.ResponseWriter, r *http.Request) {
+	products, err := s.store.ListProducts(r.Context(), backend.ProductQuery{Limit: intQuery(r, "limit", 20), Offset: intQuery(r, "offset", 0)})
+	if err != nil {
+		failErr(w, err)
+		return
+	}
+	ok(w, http.StatusOK, "products retrieved", products)
+}
+
+func (s *server) search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	minPrice, _ := strconv.ParseFloat(q.Get("min_price"), 64)
+	maxPrice, _ := strconv.ParseFloat(q.Get("max_price"), 64)
+	products, err := s.store.SearchProducts(r.Context(), backend.SearchQuery{
+		Query: q.Get("q"), Limit: intQuery(r, "limit", 20), Offset: intQuery(r, "offset", 0),
+		MinPrice: minPrice, MaxPrice: maxPrice, CategoryID: q.Get("category"),
+	})
+	if err != nil {
+		failErr(w, err)
+		return
+	}
+	ok(w, http.StatusOK, "products retrieved", products)
+}
+
+func (s *server) getProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		fail(w, http.StatusBadRequest, "invalid product id", r.PathValue("id"))
+		return
+	}
+	p, err := s.store.GetProduct(r.Context(), id)
+	if err != nil {
+		failErr(w, err)
+		return
+	}
+	ok(w, http.StatusOK, "product retrieved", p)
+}
+
+func (s *server) cart(ctx context.Context) (*models.Cart, error) {
+	cart, err := s.store.GetCart(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cart.Discounts = s.engine.Discounts(cart)
+	return cart, nil
+}
+
+func (s *server) writeCart(w http.ResponseWriter, r *http.Request, status int, message string) {
+	cart, err := s.cart(r.Context())
+	if err != nil {
+		failErr(w, err)
+		return
+	}
+	ok(w, status, message, cart)
+}
+
+func (s *server) getCart(w http.ResponseWriter, r *http.Request) {
+	s.writeCart(w, r, http.StatusOK, "cart retrieved")
+}
+
+func (s *server) addItem(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ProductID int `json:"product_id"`
+		Quantity  int `json:"quantity"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		fail(w, http.StatusBadRequest, "invalid body", err.Error())
+		return
+	}
+	if _, err := s.store.AddToCart(r.Context(), body.ProductID, body.Quantity); err != nil {
+		failErr(w, err)
+		return
+	}
+	s.writeCart(w, r, http.StatusCreated, "item added to cart")
+}
+
+func (s *server) updateItem(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	var body struct {
+		Quantity int `json:"quantity"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		fail(w, http.StatusBadRequest, "invalid body", err.Error())
+		return
+	}
+	if _, err := s.store.UpdateCartItem(r.Context(), id, body.Quantity); err != nil {
+		failErr(w, err)
+		return
+	}
+	ok(w, http.StatusOK, "cart item updated", nil)
+}
+
+func (s *server) removeItem(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	if _, err := s.store.RemoveCartItem(r.Context(), id); err != nil {
+		failErr(w, err)
+		return
+	}
+	ok(w, http.StatusOK, "cart item removed", nil)
+}
+
+func (s *server) clearCart(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.store.ClearCart(r.Context()); err != nil {
+		failErr(w, err)
+		return
+	}
+	s.engine.Clear()
+	ok(w, http.StatusOK, "cart cleared", nil)
+}
+
+func (s *server) applyCoupon(w http.ResponseWriter, r *http.Request) {
+	if s.noPromos {
+		fail(w, http.StatusNotImplemented, "not implemented", "coupons are not supported")
+		return
+	}
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		fail(w, http.StatusBadRequest, "invalid body", err.Error())
+		return
+	}
+	if !s.engine.Has(body.Code) {
+		fail(w, http.StatusNotFound, "coupon not found", fmt.Sprintf("unknown coupon code %s", body.Code))
+		return
+	}
+	cart, err := s.store.GetCart(r.Context())
+	if err != nil {
+		failErr(w, err)
+		return
+	}
+	if _, err := s.engine.Apply(body.Code, cart); err != nil {
+		fail(w, http.StatusUnprocessableEntity, "coupon not applicable", err.Error())
+		return
+	}
+	ok(w, http.StatusOK, "coupon applied", nil)
+}
+
+func (s *server) removeCoupon(w http.ResponseWriter, r *http.Request) {
+	if s.noPromos {
+		fail(w, http.StatusNotImplemented, "not implemented", "coupons are not supported")
+		return
+	}
+	if !s.engine.Remove(r.PathValue("code")) {
+		fail(w, http.StatusNotFound, "coupon not applied", fmt.Sprintf("coupon %s is not applied", r.PathValue("code")))
+		return
+	}
+	ok(w, http.StatusOK, "coupon removed", nil)
+}
+
+func (s *server) listPromotions(w http.ResponseWriter, r *http.Request) {
+	if s.noPromos {
+		fail(w, http.StatusNotImplemented, "not implemented", "promotions are not supported")
+		return
+	}
+	ok(w, http.StatusOK, "promotions retrieved", s.promos)
+}
+
+func (s *server) placeOrder(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		CouponCodes []string `json:"coupon_codes"`
+	}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			fail(w, http.StatusBadRequest, "invalid body", err.Error())
+			return
+		}
+	}
+	for _, code := range body.CouponCodes {
+		if !s.engine.IsApplied(code) {
+			fail(w, http.StatusUnprocessableEntity, "invalid coupon", fmt.Sprintf("coupon %s is not applied to the cart", code))
+			return
+		}
+	}
+	cart, err := s.cart(r.Context())
+	if err != nil {
+		failErr(w, err)
+		return
+	}
+	discount := 0.0
+	for _, d := range cart.Discounts {
+		discount += d.Amount
+	}
+	order, err := s.store.PlaceOrder(r.Context(), backend.PlaceOrderRequest{
+		IdempotencyKey: r.Header.Get("Idempotency-Key"),
+		CouponCodes:    body.CouponCodes,
+	})
+	if err != nil {
+		failErr(w, err)
+		return
+	}
+	s.engine.Clear()
+	s.mu.Lock()
+	if _, seen := s.orderAdj[order.Id]; !seen {
+		s.orderAdj[order.Id] = discount
+	}
+	s.mu.Unlock()
+	ok(w, http.StatusCreated, "order placed", s.adjust(*order))
+}
+
+func (s *server) adjust(order models.Order) models.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d := s.orderAdj[order.Id]; d > 0 {
+		order.DiscountAmount = d
+		order.Total = max(order.Subtotal-d, 0)
+	}
+	return order
+}
+
+func (s *server) listOrders(w http.ResponseWriter, r *http.Request) {
+	q := backend.OrderQuery{Limit: intQuery(r, "limit", 10), Offset: intQuery(r, "offset", 0), Status: r.URL.Query().Get("status")}
+	if v := r.URL.Query().Get("from"); v != "" {
+		q.From, _ = time.Parse(time.RFC3339, v)
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		q.To, _ = time.Parse(time.RFC3339, v)
+	}
+	orders, err := s.store.ListOrders(r.Context(), q)
+	if err != nil {
+		failErr(w, err)
+		return
+	}
+	for i := range orders {
+		orders[i] = s.adjust(orders[i])
+	}
+	ok(w, http.StatusOK, "orders retrieved", orders)
+}
+
+func (s *server) getOrder(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	order, err := s.store.GetOrder(r.Context(), id)
+	if err != nil {
+		failErr(w, err)
+		return
+	}
+	ok(w, http.StatusOK, "order retrieved", s.adjust(*order))
+}
+
+func (s *server) getWishlist(w http.ResponseWriter, r *http.Request) {
+	if s.noWishlist {
+		fail(w, http.StatusNotImplemented, "not implemented", "wishlists are not supported")
+		return
+	}
+	s.mu.Lock()
+	items := slices.Clone(s.wishlist)
+	s.mu.Unlock()
+	for i := range items {
+		if p, err := s.store.GetProduct(r.Context(), items[i].Product.Id); err == nil {
+			items[i].Product = *p
+		}
+	}
+	ok(w, http.StatusOK, "wishlist retrieved", models.Wishlist{Items: items})
+}
+
+func (s *server) addWish(w http.ResponseWriter, r *http.Request) {
+	if s.noWishlist {
+		fail(w, http.StatusNotImplemented, "not implemented", "wishlists are not supported")
+		return
+	}
+	var body struct {
+		ProductID int `json:"product_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		fail(w, http.StatusBadRequest, "invalid body", err.Error())
+		return
+	}
+	p, err := s.store.GetProduct(r.Context(), body.ProductID)
+	if err != nil {
+		failErr(w, err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if slices.ContainsFunc(s.wishlist, func(i models.WishlistItem) bool { return i.Product.Id == p.Id }) {
+		fail(w, http.StatusConflict, "already in wishlist", fmt.Sprintf("product %d is already in the wishlist", p.Id))
+		return
+	}
+	s.nextWish++
+	s.wishlist = append(s.wishlist, models.WishlistItem{Id: s.nextWish, Product: *p, CreatedAt: time.Now().UTC()})
+	ok(w, http.StatusCreated, "added to wishlist", nil)
+}
+
+func (s *server) removeWish(w http.ResponseWriter, r *http.Request) {
+	if s.noWishlist {
+		fail(w, http.StatusNotImplemented, "not implemented", "wishlists are not supported")
+		return
+	}
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx := slices.IndexFunc(s.wishlist, func(i models.WishlistItem) bool { return i.Product.Id == id })
+	if idx < 0 {
+		fail(w, http.StatusNotFound, "not found", fmt.Sprintf("product %d is not in the wishlist", id))
+		return
+	}
+	s.wishlist = slices.Delete(s.wishlist, idx, idx+1)
+	ok(w, http.StatusOK, "removed from wishlist", nil)
+}
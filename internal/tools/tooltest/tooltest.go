@@ -0,0 +1,209 @@
+// Package tooltest runs toolsets against a REST backend whose HTTP traffic is
+// replayed from cassettes, so tool tests need no live Cartopher API.
+//
+// Cassettes live in testdata/cassettes of the package under test. To record
+// them again, run the tests with -record:
+//
+//	go test ./internal/tools/... -record
+//
+// Each test then records against its own fakeapi server over
+// fixtures/store.json. Set API_URL and AUTH_TOKEN to record against a real
+// Cartopher API with that data instead.
+package tooltest
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend/rest"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/cassette"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/client"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/tooltest/fakeapi"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var record = flag.Bool("record", false, "record cassettes against API_URL, or a fake API, instead of replaying them")
+
+// baseURL is the API address stored in cassettes, whichever API they were
+// recorded against.
+const baseURL = "http://cartopher.test"
+
+// Options configures the fixture Setup builds.
+type Options struct {
+	// API configures the fake API cassettes are recorded against, such as
+	// one without a wishlist.
+	API fakeapi.Options
+	// KeepCart leaves the cart as the API has it instead of emptying it
+	// first, for toolsets that never touch the cart.
+	KeepCart bool
+}
+
+// Setup builds the fixture of a toolset test: a backend replaying
+// testdata/cassettes/<name>.json, with the cart emptied unless opts.KeepCart
+// is set, and an empty registry for the toolsets under test.
+func Setup(t testing.TB, name string, opts Options) (*mcp.Registry, backend.Backend) {
+	t.Helper()
+
+	store := Backend(t, name, opts.API)
+	if !opts.KeepCart {
+		if _, err := store.ClearCart(t.Context()); err != nil {
+			t.Fatalf("failed to empty cart: %s", err)
+		}
+	}
+	return mcp.NewRegistry(Logger()), store
+}
+
+// Backend returns a REST backend that replays testdata/cassettes/<name>.json,
+// recorded against a fake API configured with api.
+func Backend(t testing.TB, name string, api fakeapi.Options) backend.Backend {
+	t.Helper()
+
+	mode := cassette.ModeReplay
+	token := "test-token"
+	var target *url.URL
+	if *record {
+		mode = cassette.ModeRecord
+		target = recordTarget(t, api)
+		if apiToken := os.Getenv("AUTH_TOKEN"); apiToken != "" {
+			token = apiToken
+		}
+	}
+
+	recorder, err := cassette.NewRecorder(cassette.Config{
+		Path: filepath.Join("testdata", "cassettes", name+".json"),
+		Mode: mode,
+	})
+	if err != nil {
+		t.Fatalf("failed to load cassette: %s", err)
+	}
+
+	restClient, err := client.NewRestClient(baseURL, token, client.Options{
+		WrapTransport: func(next http.RoundTripper) http.RoundTripper {
+			if target != nil {
+				next = &rewrite{target: target, next: next}
+			}
+			return recorder.Wrap(next)
+		},
+	}, Logger())
+	if err != nil {
+		t.Fatalf("failed to create REST client: %s", err)
+	}
+	t.Cleanup(restClient.Close)
+
+	return rest.New(restClient)
+}
+
+// recordTarget returns API_URL, or else the address of a fresh fake API.
+func recordTarget(t testing.TB, api fakeapi.Options) *url.URL {
+	t.Helper()
+
+	apiURL := os.Getenv("API_URL")
+	if apiURL == "" {
+		api.Fixture = filepath.Join(moduleRoot(t), "fixtures", "store.json")
+		handler, err := fakeapi.New(api)
+		if err != nil {
+			t.Fatalf("failed to start fake API: %s", err)
+		}
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+		apiURL = server.URL
+	}
+
+	target, err := url.Parse(apiURL)
+	if err != nil {
+		t.Fatalf("invalid API_URL: %s", err)
+	}
+	return target
+}
+
+func moduleRoot(t testing.TB) string {
+	t.Helper()
+
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			t.Fatal("go.mod not found above the test directory")
+		}
+		dir = parent
+	}
+}
+
+func Logger() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+// Call runs a registered tool with JSON arguments and fails the test when the
+// tool returns an error instead of a result.
+func Call(t testing.TB, reg *mcp.Registry, tool, args string) mcp.CallToolResult {
+	t.Helper()
+
+	result, err := Try(t, reg, tool, args)
+	if err != nil {
+		t.Fatalf("%s failed: %s", tool, err)
+	}
+	return result
+}
+
+// Try runs a registered tool with JSON arguments and returns its error.
+func Try(t testing.TB, reg *mcp.Registry, tool, args string) (mcp.CallToolResult, error) {
+	t.Helper()
+
+	parsed := map[string]any{}
+	if args != "" {
+		if err := json.Unmarshal([]byte(args), &parsed); err != nil {
+			t.Fatalf("invalid arguments for %s: %s", tool, err)
+		}
+	}
+	return reg.ExecuteTool(t.Context(), tool, parsed)
+}
+
+func Text(result mcp.CallToolResult) string {
+	texts := make([]string, 0, len(result.Content))
+	for _, c := range result.Content {
+		texts = append(texts, c.Text)
+	}
+	return strings.Join(texts, "\n")
+}
+
+// Contains fails the test unless the result text contains every want.
+func Contains(t testing.TB, result mcp.CallToolResult, want ...string) {
+	t.Helper()
+
+	text := Text(result)
+	for _, w := range want {
+		if !strings.Contains(text, w) {
+			t.Errorf("result does not contain %q:\n%s", w, text)
+		}
+	}
+}
+
+// rewrite sends requests addressed to baseURL to the API being recorded.
+type rewrite struct {
+	target *url.URL
+	next   http.RoundTripper
+}
+
+func (r *rewrite) RoundTrip(req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.URL.Scheme = r.target.Scheme
+	out.URL.Host = r.target.Host
+	out.URL.Path = strings.TrimSuffix(r.target.Path, "/") + req.URL.Path
+	out.URL.RawPath = ""
+	out.Host = ""
+	return r.next.RoundTrip(out)
+}
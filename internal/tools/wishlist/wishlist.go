@@ -0,0 +1,378 @@
+package wishlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/cart"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/products"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Options struct {
+	// Local stores the wishlist when the backend has no wishlist API.
+	Local backend.Wishlist
+	// Snapshots records the cart before move_to_cart changes it.
+	Snapshots *cart.Snapshots
+	// Money formats prices in the session or requested currency.
+	Money *money.Display
+}
+
+type WishlistToolset struct {
+	reg       *mcp.Registry
+	logger    *slog.Logger
+	backend   backend.Backend
+	local     backend.Wishlist
+	resolver  *products.Resolver
+	snapshots *cart.Snapshots
+	money     *money.Display
+
+	mu     sync.Mutex
+	source backend.Wishlist
+}
+
+func (w *WishlistToolset) registerTools() {
+	productProperties := map[string]mcp.Property{
+		"product_id": {
+			Type:        "number",
+			Description: "ID of the product",
+		},
+		"sku": {
+			Type:        "string",
+			Description: "SKU of the product, if product_id is not known",
+		},
+		"name": {
+			Type:        "string",
+			Description: "Name of the product, if neither product_id nor sku is known. If several products match, the candidates are returned instead",
+		},
+	}
+
+	w.reg.Register(mcp.Tool{
+		Name:        "add_to_wishlist",
+		Title:       "Add to Wishlist",
+		Description: "Save a product to the user's wishlist by product_id, sku or name",
+		InputSchema: mcp.InputSchema{
+			Type:       "object",
+			Properties: productProperties,
+			Required:   []string{},
+		},
+		Annotations: mcp.Mutating(false, true),
+	}, w.handleAddToWishlist)
+
+	w.reg.Register(mcp.Tool{
+		Name:        "view_wishlist",
+		Title:       "View Wishlist",
+		Description: "View the products saved to the user's wishlist with their current price and availability",
+		InputSchema: mcp.InputSchema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"currency": {
+					Type:        "string",
+					Description: "Currency to show prices in, e.g. EUR or GBP (default: the session currency set with set_currency)",
+				},
+			},
+			Required: []string{},
+		},
+		Annotations: mcp.ReadOnly(),
+	}, w.handleViewWishlist)
+
+	w.reg.Register(mcp.Tool{
+		Name:        "move_to_cart",
+		Title:       "Move to Cart",
+		Description: "Move a product from the wishlist into the shopping cart (requires authentication)",
+		InputSchema: mcp.InputSchema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"product_id": {
+					Type:        "number",
+					Description: "ID of the wishlist product to move, as shown by view_wishlist",
+				},
+				"quantity": {
+					Type:        "number",
+					Description: "Quantity to add to the cart (default: 1)",
+				},
+			},
+			Required: []string{"product_id"},
+		},
+		Annotations: mcp.Mutating(false, false),
+	}, w.snapshots.Track(w.backend, "move_to_cart", w.handleMoveToCart))
+
+	w.reg.Register(mcp.Tool{
+		Name:        "remove_from_wishlist",
+		Title:       "Remove from Wishlist",
+		Description: "Remove a product from the user's wishlist",
+		InputSchema: mcp.InputSchema{
+			Type: "object",
+			Properties: map[string]mcp.Property{
+				"product_id": {
+					Type:        "number",
+					Description: "ID of the wishlist product to remove, as shown by view_wishlist",
+				},
+			},
+			Required: []string{"product_id"},
+		},
+		Annotations: mcp.Mutating(true, true),
+	}, w.handleRemoveFromWishlist)
+}
+
+// wishlist picks the backend wishlist API when it exists and the local store
+// when the backend reports it has none. The choice is made on first use and
+// kept for the session; any other failure is returned without deciding, so
+// items are never split between the two.
+func (w *WishlistToolset) wishlist(ctx context.Context) (backend.Wishlist, *models.Wishlist, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.source != nil {
+		list, err := w.source.GetWishlist(ctx)
+		return w.source, list, err
+	}
+
+	list, err := w.backend.GetWishlist(ctx)
+	switch {
+	case err == nil:
+		w.source = w.backend
+		w.logger.InfoContext(ctx, "Using wishlist source", "source", "backend")
+		return w.source, list, nil
+	case errors.Is(err, backend.ErrUnsupported):
+		w.source = w.local
+		w.logger.InfoContext(ctx, "Using wishlist source", "source", "local")
+		list, err = w.source.GetWishlist(ctx)
+		return w.source, list, err
+	default:
+		return nil, nil, err
+	}
+}
+
+func (w *WishlistToolset) handleAddToWishlist(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	w.logger.InfoContext(ctx, "Adding to wishlist", "args", args)
+
+	ref := products.Ref{}
+	ref.SKU, _ = args["sku"].(string)
+	ref.Name, _ = args["name"].(string)
+	if v, ok := args["product_id"]; ok {
+		productIDFloat, ok := v.(float64)
+		if !ok {
+			return mcp.CallToolResult{}, fmt.Errorf("invalid product_id; %+v", v)
+		}
+		ref.ProductID = int(productIDFloat)
+	}
+
+	product, err := w.resolver.Resolve(ctx, ref)
+	var ambiguous *products.AmbiguousError
+	if errors.As(err, &ambiguous) {
+		return mcp.NewToolCallError(ambiguous.Prompt(w.formatter())), nil
+	}
+	if errors.Is(err, backend.ErrInvalid) {
+		return mcp.NewToolCallError("product_id, sku or name is required"), nil
+	}
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to find %s: %w", ref, err)
+	}
+
+	source, list, err := w.wishlist(ctx)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch wishlist: %w", err)
+	}
+	if findItem(list, product.Id) != nil {
+		return textResult(fmt.Sprintf("%s (product %d) is already in the wishlist", product.Name, product.Id)), nil
+	}
+
+	list, err = source.AddToWishlist(ctx, product.Id)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to add to wishlist: %w", err)
+	}
+
+	w.logger.InfoContext(ctx, "Added to wishlist", "product_id", product.Id)
+
+	return textResult(fmt.Sprintf("✓ Saved %s (product %d) to the wishlist\n\n%s", product.Name, product.Id, w.formatWishlist(list))), nil
+}
+
+func (w *WishlistToolset) handleViewWishlist(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	w.logger.InfoContext(ctx, "Viewing wishlist", "args", args)
+
+	currency, _ := args["currency"].(string)
+	format, err := w.money.Formatter(currency)
+	if err != nil {
+		return mcp.NewToolCallError(err.Error()), nil
+	}
+
+	_, list, err := w.wishlist(ctx)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch wishlist: %w", err)
+	}
+
+	return textResult(renderWishlist(list, format) + format.Note()), nil
+}
+
+func (w *WishlistToolset) handleMoveToCart(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	w.logger.InfoContext(ctx, "Moving wishlist item to cart", "args", args)
+
+	productIDFloat, ok := args["product_id"].(float64)
+	if !ok {
+		return mcp.CallToolResult{}, fmt.Errorf("invalid product_id; %+v", args["product_id"])
+	}
+	productID := int(productIDFloat)
+
+	quantity := 1
+	if q, ok := args["quantity"].(float64); ok && q >= 1 {
+		quantity = int(q)
+	}
+
+	source, list, err := w.wishlist(ctx)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch wishlist: %w", err)
+	}
+	item := findItem(list, productID)
+	if item == nil {
+		return mcp.NewToolCallError(fmt.Sprintf("Product %d is not in the wishlist. Call view_wishlist to see the saved products.", productID)), nil
+	}
+
+	product, err := w.backend.GetProduct(ctx, productID)
+	if errors.Is(err, backend.ErrNotFound) {
+		return mcp.NewToolCallError(fmt.Sprintf("Product %d no longer exists in the catalog; remove it with remove_from_wishlist", productID)), nil
+	}
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch product %d: %w", productID, err)
+	}
+	if !product.IsActive {
+		return mcp.NewToolCallError(fmt.Sprintf("%s is no longer available", product.Name)), nil
+	}
+	if product.Stock < quantity {
+		return mcp.NewToolCallError(fmt.Sprintf("Only %d of %s in stock", product.Stock, product.Name)), nil
+	}
+
+	if _, err := w.backend.AddToCart(ctx, productID, quantity); err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to add to cart: %w", err)
+	}
+
+	// The product is in the cart at this point, so a failed removal is only
+	// reported; retrying move_to_cart would add it a second time.
+	text := fmt.Sprintf("✓ Moved %s (product %d, quantity: %d) to the cart", product.Name, productID, quantity)
+	if _, err := source.RemoveFromWishlist(ctx, productID); err != nil {
+		w.logger.WarnContext(ctx, "Failed to remove moved item from wishlist", "product_id", productID, "error", err)
+		text += "\n\n⚠ It could not be removed from the wishlist; call remove_from_wishlist to remove it."
+	}
+
+	w.logger.InfoContext(ctx, "Moved wishlist item to cart", "product_id", productID, "quantity", quantity)
+
+	return textResult(text), nil
+}
+
+func (w *WishlistToolset) handleRemoveFromWishlist(ctx context.Context, args map[string]any) (mcp.CallToolResult, error) {
+	w.logger.InfoContext(ctx, "Removing from wishlist", "args", args)
+
+	productIDFloat, ok := args["product_id"].(float64)
+	if !ok {
+		return mcp.CallToolResult{}, fmt.Errorf("invalid product_id; %+v", args["product_id"])
+	}
+	productID := int(productIDFloat)
+
+	source, list, err := w.wishlist(ctx)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to fetch wishlist: %w", err)
+	}
+	if findItem(list, productID) == nil {
+		return mcp.NewToolCallError(fmt.Sprintf("Product %d is not in the wishlist", productID)), nil
+	}
+
+	list, err = source.RemoveFromWishlist(ctx, productID)
+	if err != nil {
+		return mcp.CallToolResult{}, fmt.Errorf("failed to remove from wishlist: %w", err)
+	}
+
+	w.logger.InfoContext(ctx, "Removed from wishlist", "product_id", productID)
+
+	return textResult(fmt.Sprintf("✓ Removed product %d from the wishlist\n\n%s", productID, w.formatWishlist(list))), nil
+}
+
+func findItem(list *models.Wishlist, productID int) *models.WishlistItem {
+	for i := range list.Items {
+		if list.Items[i].Product.Id == productID {
+			return &list.Items[i]
+		}
+	}
+	return nil
+}
+
+// formatWishlist renders the wishlist in the session currency.
+func (w *WishlistToolset) formatWishlist(list *models.Wishlist) string {
+	format := w.formatter()
+	return renderWishlist(list, format) + format.Note()
+}
+
+func (w *WishlistToolset) formatter() money.Formatter {
+	format, _ := w.money.Formatter("")
+	return format
+}
+
+func renderWishlist(list *models.Wishlist, format money.Formatter) string {
+	if len(list.Items) == 0 {
+		return "💝 Your wishlist is empty"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "💝 Wishlist (%d items):\n\n", len(list.Items))
+	for i, item := range list.Items {
+		p := item.Product
+		if p.Name == "" {
+			fmt.Fprintf(&b, "%d. Product %d - no longer in the catalog\n", i+1, p.Id)
+			continue
+		}
+
+		status := fmt.Sprintf("%d in stock", p.Stock)
+		switch {
+		case !p.IsActive:
+			status = "unavailable"
+		case p.Stock == 0:
+			status = "out of stock"
+		}
+		fmt.Fprintf(&b, "%d. %s (product %d) - %s, %s", i+1, p.Name, p.Id, format.Format(p.Price), status)
+		if !item.CreatedAt.IsZero() {
+			fmt.Fprintf(&b, ", saved %s", item.CreatedAt.Format(time.DateOnly))
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func textResult(text string) mcp.CallToolResult {
+	return mcp.CallToolResult{
+		Content: []mcp.Content{
+			{
+				Type: "text",
+				Text: text,
+			},
+		},
+	}
+}
+
+func NewWishlistToolset(reg *mcp.Registry, store backend.Backend, opts Options, logger *slog.Logger) *WishlistToolset {
+	snapshots := opts.Snapshots
+	if snapshots == nil {
+		snapshots = cart.NewSnapshots(cart.DefaultSnapshotLimit, nil, logger)
+	}
+	display := opts.Money
+	if display == nil {
+		display, _ = money.NewDisplay(money.USD, nil)
+	}
+
+	wt := &WishlistToolset{
+		reg:       reg,
+		backend:   store,
+		local:     opts.Local,
+		resolver:  products.NewResolver(store),
+		snapshots: snapshots,
+		money:     display,
+		logger:    logger,
+	}
+	wt.registerTools()
+	return wt
+}
@@ -0,0 +1,112 @@
+package wishlist
+
+import (
+	"errors"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/cart"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/tooltest"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/tooltest/fakeapi"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/wishlist"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newToolset registers the wishlist tools, and the cart tools move_to_cart
+// works with, against the cassette's backend, starting from an empty cart. It
+// also returns the path of the local wishlist file.
+func newToolset(t *testing.T, cassette string, api fakeapi.Options) (*mcp.Registry, string) {
+	t.Helper()
+
+	reg, store := tooltest.Setup(t, cassette, tooltest.Options{API: api})
+	path := filepath.Join(t.TempDir(), "wishlist.json")
+	snapshots := cart.NewSnapshots(cart.DefaultSnapshotLimit, nil, tooltest.Logger())
+	cart.NewCartToolset(reg, store, cart.Options{Snapshots: snapshots}, tooltest.Logger())
+	NewWishlistToolset(reg, store, Options{
+		Local:     wishlist.NewLocalStore(path, "", "test-token", store),
+		Snapshots: snapshots,
+	}, tooltest.Logger())
+	return reg, path
+}
+
+func TestWishlist(t *testing.T) {
+	reg, path := newToolset(t, "wishlist", fakeapi.Options{})
+
+	result := tooltest.Call(t, reg, "view_wishlist", "")
+	tooltest.Contains(t, result, "Your wishlist is empty")
+
+	result = tooltest.Call(t, reg, "add_to_wishlist", `{"product_id": 6}`)
+	tooltest.Contains(t, result, "Saved Burr Grinder (product 6) to the wishlist", "Burr Grinder (product 6) - $129.00, 3 in stock")
+
+	result = tooltest.Call(t, reg, "add_to_wishlist", `{"product_id": 9}`)
+	tooltest.Contains(t, result, "Travel Mug (product 9) - $19.00, unavailable")
+
+	result = tooltest.Call(t, reg, "add_to_wishlist", `{"product_id": 6}`)
+	tooltest.Contains(t, result, "already in the wishlist")
+
+	result = tooltest.Call(t, reg, "move_to_cart", `{"product_id": 9}`)
+	if !result.IsError {
+		t.Errorf("expected an unavailable product to stay in the wishlist, got %q", tooltest.Text(result))
+	}
+
+	result = tooltest.Call(t, reg, "move_to_cart", `{"product_id": 6}`)
+	tooltest.Contains(t, result, "Moved Burr Grinder (product 6, quantity: 1) to the cart")
+
+	result = tooltest.Call(t, reg, "remove_from_wishlist", `{"product_id": 9}`)
+	tooltest.Contains(t, result, "Removed product 9 from the wishlist", "Your wishlist is empty")
+
+	result = tooltest.Call(t, reg, "undo_last_cart_change", "")
+	tooltest.Contains(t, result, "Undid move_to_cart", "Your cart is empty")
+
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the local store should be unused while the backend has a wishlist, got %v", err)
+	}
+}
+
+func TestWishlistFallsBackToLocalStore(t *testing.T) {
+	reg, path := newToolset(t, "wishlist_local", fakeapi.Options{Wishlist: fakeapi.WishlistUnsupported})
+
+	result := tooltest.Call(t, reg, "add_to_wishlist", `{"product_id": 4}`)
+	tooltest.Contains(t, result, "Saved Pour-Over Dripper (product 4) to the wishlist", "Pour-Over Dripper (product 4) - $29.00, 18 in stock")
+
+	// Without a configured user the wishlist is kept under the backend's user
+	// id, which survives token rotation.
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bs), `"user:1"`) {
+		t.Errorf("expected the wishlist to be stored for user:1, got %s", bs)
+	}
+
+	result = tooltest.Call(t, reg, "move_to_cart", `{"product_id": 4, "quantity": 2}`)
+	tooltest.Contains(t, result, "Moved Pour-Over Dripper (product 4, quantity: 2) to the cart")
+
+	result = tooltest.Call(t, reg, "view_wishlist", "")
+	tooltest.Contains(t, result, "Your wishlist is empty")
+}
+
+func TestWishlistFallsBackWithoutWishlistRoute(t *testing.T) {
+	reg, path := newToolset(t, "wishlist_missing", fakeapi.Options{Wishlist: fakeapi.WishlistMissing})
+
+	// A wishlist an earlier version kept under the hash of the test token.
+	legacy := `{"users": {"4c5dc9b7708905f7": [{"product_id": 6, "added_at": "2026-10-01T09:00:00Z"}]}}`
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	result := tooltest.Call(t, reg, "view_wishlist", "")
+	tooltest.Contains(t, result, "Burr Grinder (product 6)")
+
+	result = tooltest.Call(t, reg, "add_to_wishlist", `{"product_id": 4}`)
+	tooltest.Contains(t, result, "Saved Pour-Over Dripper (product 4) to the wishlist")
+
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bs), `"user:1"`) || strings.Contains(string(bs), "4c5dc9b7708905f7") {
+		t.Errorf("expected the token-keyed wishlist to move to user:1, got %s", bs)
+	}
+}
@@ -0,0 +1,279 @@
+package wishlist
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/models"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const anonymous = "anonymous"
+
+type entry struct {
+	ProductID int       `json:"product_id"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+type file struct {
+	Users map[string][]entry `json:"users"`
+}
+
+// LocalStore keeps wishlists in a JSON file for backends without a wishlist
+// API. Products are looked up in the catalog when the wishlist is read, so
+// prices and stock are always current.
+type LocalStore struct {
+	mu       sync.Mutex
+	path     string
+	identity string
+	legacy   string
+	migrated bool
+	store    backend.Backend
+}
+
+// user returns the key the user's wishlist is stored under: the configured
+// user key, or else the user id the backend reports for the cart. Auth tokens
+// are never used, since a rotated token would orphan the wishlist.
+func (s *LocalStore) user(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	identity, migrated := s.identity, s.migrated
+	s.mu.Unlock()
+	if identity != "" && migrated {
+		return identity, nil
+	}
+
+	if identity == "" {
+		cart, err := s.store.GetCart(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to identify wishlist user: %w", err)
+		}
+		identity = anonymous
+		if cart.UserId != 0 {
+			identity = "user:" + strconv.Itoa(cart.UserId)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.identity = identity
+	if !s.migrated {
+		if err := s.migrate(identity); err != nil {
+			return "", fmt.Errorf("failed to migrate wishlist: %w", err)
+		}
+		s.migrated = true
+	}
+	return identity, nil
+}
+
+// migrate moves a wishlist stored under the key earlier versions derived
+// from the auth token to identity, merging it with what identity holds.
+func (s *LocalStore) migrate(identity string) error {
+	if s.legacy == "" || s.legacy == identity {
+		return nil
+	}
+
+	f, err := s.load()
+	if err != nil {
+		return err
+	}
+	old, ok := f.Users[s.legacy]
+	if !ok {
+		return nil
+	}
+
+	entries := f.Users[identity]
+	for _, e := range old {
+		if !slices.ContainsFunc(entries, func(c entry) bool { return c.ProductID == e.ProductID }) {
+			entries = append(entries, e)
+		}
+	}
+	f.Users[identity] = entries
+	delete(f.Users, s.legacy)
+	return s.save(f)
+}
+
+// tokenIdentity is the key earlier versions stored a wishlist under: a hash
+// of the auth token.
+func tokenIdentity(token string) string {
+	if token == "" {
+		return anonymous
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (s *LocalStore) GetWishlist(ctx context.Context) (*models.Wishlist, error) {
+	user, err := s.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	entries, err := s.entries(user)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return s.hydrate(ctx, entries)
+}
+
+func (s *LocalStore) AddToWishlist(ctx context.Context, productID int) (*models.Wishlist, error) {
+	user, err := s.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.update(user, func(entries []entry) ([]entry, error) {
+		if slices.ContainsFunc(entries, func(e entry) bool { return e.ProductID == productID }) {
+			return entries, nil
+		}
+		return append(entries, entry{ProductID: productID, AddedAt: time.Now().UTC()}), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.hydrate(ctx, entries)
+}
+
+func (s *LocalStore) RemoveFromWishlist(ctx context.Context, productID int) (*models.Wishlist, error) {
+	user, err := s.user(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.update(user, func(entries []entry) ([]entry, error) {
+		idx := slices.IndexFunc(entries, func(e entry) bool { return e.ProductID == productID })
+		if idx < 0 {
+			return nil, fmt.Errorf("%w: product %d is not in the wishlist", backend.ErrNotFound, productID)
+		}
+		return slices.Delete(entries, idx, idx+1), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.hydrate(ctx, entries)
+}
+
+// hydrate turns stored entries into wishlist items. Products that have been
+// deleted from the catalog are kept with only their ID so the user can see
+// and remove them.
+func (s *LocalStore) hydrate(ctx context.Context, entries []entry) (*models.Wishlist, error) {
+	wishlist := &models.Wishlist{Items: make([]models.WishlistItem, 0, len(entries))}
+	for i, e := range entries {
+		item := models.WishlistItem{
+			Id:        i + 1,
+			Product:   models.Product{Id: e.ProductID},
+			CreatedAt: e.AddedAt,
+		}
+
+		product, err := s.store.GetProduct(ctx, e.ProductID)
+		switch {
+		case err == nil:
+			item.Product = *product
+		case !errors.Is(err, backend.ErrNotFound):
+			return nil, fmt.Errorf("failed to fetch product %d: %w", e.ProductID, err)
+		}
+
+		wishlist.Items = append(wishlist.Items, item)
+	}
+	return wishlist, nil
+}
+
+func (s *LocalStore) update(user string, fn func([]entry) ([]entry, error)) ([]entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := fn(f.Users[user])
+	if err != nil {
+		return nil, err
+	}
+	f.Users[user] = entries
+
+	if err := s.save(f); err != nil {
+		return nil, fmt.Errorf("failed to save wishlist: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *LocalStore) entries(user string) ([]entry, error) {
+	f, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return f.Users[user], nil
+}
+
+func (s *LocalStore) load() (*file, error) {
+	f := &file{Users: make(map[string][]entry)}
+
+	bs, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(bs, f); err != nil {
+		return nil, fmt.Errorf("invalid wishlist file %s: %w", s.path, err)
+	}
+	if f.Users == nil {
+		f.Users = make(map[string][]entry)
+	}
+	return f, nil
+}
+
+// save writes through a temporary file so a crash never leaves a truncated
+// wishlist behind.
+func (s *LocalStore) save(f *file) error {
+	bs, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(bs, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// NewLocalStore keeps the wishlist of user in the file at path. An empty user
+// is resolved from the backend on first use. A wishlist an earlier version
+// stored under token is moved to the user then.
+func NewLocalStore(path, user, token string, store backend.Backend) *LocalStore {
+	return &LocalStore{
+		path:     path,
+		identity: user,
+		legacy:   tokenIdentity(token),
+		store:    store,
+	}
+}
@@ -1,18 +1,37 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"github.com/saleh-ghazimoradi/CartopherCopilot/config"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend/memory"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/backend/rest"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/checkout"
 	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/client"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/correlation"
 	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/mcp"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/money"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/openapi"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/promotions"
 	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/cart"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/currency"
 	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/orders"
 	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/products"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/toolerr"
+	wishlisttools "github.com/saleh-ghazimoradi/CartopherCopilot/internal/tools/wishlist"
+	"github.com/saleh-ghazimoradi/CartopherCopilot/internal/wishlist"
 	"log/slog"
+	"mime"
 	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"syscall"
 )
 
 func main() {
-	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	logger := slog.New(correlation.NewHandler(slog.NewJSONHandler(os.Stderr, nil)))
 
 	cfg, err := config.GetConfig()
 	if err != nil {
@@ -22,19 +41,229 @@ func main() {
 
 	logger.Info("Starting CartopherCopilot Server", "api_url", cfg.APIURL, "auth_token_configured", cfg.AuthToken)
 
-	restClient := client.NewRestClient(cfg.APIURL, cfg.AuthToken, logger)
+	if cfg.TLSInsecureSkipVerify && cfg.Environment != "development" {
+		logger.Error("TLS_INSECURE_SKIP_VERIFY is only allowed when ENVIRONMENT=development", "environment", cfg.Environment)
+		os.Exit(1)
+	}
+
+	restClient, err := client.NewRestClient(cfg.APIURL, cfg.AuthToken, client.Options{
+		ConnectTimeout: cfg.HTTPConnectTimeout,
+		HeaderTimeout:  cfg.HTTPHeaderTimeout,
+		Timeout:        cfg.HTTPTimeout,
+		Retry: client.RetryPolicy{
+			MaxAttempts:    cfg.RetryMaxAttempts,
+			InitialBackoff: cfg.RetryInitialBackoff,
+			MaxBackoff:     cfg.RetryMaxBackoff,
+			Multiplier:     cfg.RetryMultiplier,
+		},
+		Breaker: client.BreakerConfig{
+			FailureThreshold: cfg.BreakerFailureThreshold,
+			OpenTimeout:      cfg.BreakerOpenTimeout,
+			HalfOpenMaxCalls: cfg.BreakerHalfOpenMaxCalls,
+		},
+		StrictDecoding: cfg.StrictDecoding,
+		Cache: client.CacheConfig{
+			MaxEntries: cfg.CacheMaxEntries,
+			TTL:        cfg.CacheTTL,
+		},
+		RateLimits: rateLimitConfig(cfg),
+		TLS: client.TLSConfig{
+			CAFile:             cfg.TLSCAFile,
+			CertFile:           cfg.TLSCertFile,
+			KeyFile:            cfg.TLSKeyFile,
+			MinVersion:         cfg.TLSMinVersion,
+			InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+			ReloadInterval:     cfg.TLSReloadInterval,
+		},
+		Proxy: client.ProxyConfig{
+			URL:     cfg.ProxyURL,
+			NoProxy: cfg.NoProxy,
+		},
+		StatsInterval: cfg.ClientStatsInterval,
+	}, logger)
+	if err != nil {
+		logger.Error("failed to create REST client", "error", err.Error())
+		os.Exit(1)
+	}
+	defer restClient.Close()
 
 	toolRegistry := mcp.NewRegistry(logger)
 
-	products.NewProductToolSet(toolRegistry, restClient, logger)
-	cart.NewCartToolset(toolRegistry, restClient, logger)
-	orders.NewOrderToolset(toolRegistry, restClient, logger)
+	store, err := newBackend(cfg, restClient)
+	if err != nil {
+		logger.Error("failed to create backend", "backend", cfg.Backend, "error", err.Error())
+		os.Exit(1)
+	}
+
+	display, err := newDisplay(cfg)
+	if err != nil {
+		logger.Error("failed to set up currencies", "file", cfg.ExchangeRatesFile, "error", err.Error())
+		os.Exit(1)
+	}
+
+	promotionEngine, err := promotions.Load(cfg.PromotionsFile, display.Store())
+	if err != nil {
+		logger.Error("failed to load promotions", "file", cfg.PromotionsFile, "error", err.Error())
+		os.Exit(1)
+	}
+
+	checkoutRules, err := checkout.Load(cfg.CheckoutFile, display.Store())
+	if err != nil {
+		logger.Error("failed to load checkout rules", "file", cfg.CheckoutFile, "error", err.Error())
+		os.Exit(1)
+	}
+
+	cartSnapshots := cart.NewSnapshots(cfg.CartSnapshotLimit, promotionEngine, logger)
+
+	products.NewProductToolSet(toolRegistry, store, products.Options{
+		Money: display,
+	}, logger)
+	cart.NewCartToolset(toolRegistry, store, cart.Options{
+		BulkConcurrency: cfg.BulkAddConcurrency,
+		BulkRollback:    cfg.BulkAddRollback,
+		Promotions:      promotionEngine,
+		Snapshots:       cartSnapshots,
+		Checkout:        checkoutRules,
+		Money:           display,
+	}, logger)
+	orders.NewOrderToolset(toolRegistry, store, orders.Options{
+		Promotions: promotionEngine,
+		Snapshots:  cartSnapshots,
+		Money:      display,
+	}, logger)
+
+	wishlistFile, err := wishlistPath(cfg)
+	if err != nil {
+		logger.Error("failed to locate wishlist file", "error", err.Error())
+		os.Exit(1)
+	}
+	wishlisttools.NewWishlistToolset(toolRegistry, store, wishlisttools.Options{
+		Local:     wishlist.NewLocalStore(wishlistFile, cfg.WishlistUser, cfg.AuthToken, store),
+		Snapshots: cartSnapshots,
+		Money:     display,
+	}, logger)
+	currency.NewCurrencyToolset(toolRegistry, display, logger)
+
+	if cfg.OpenAPISpec != "" {
+		doc, err := openapi.Load(context.Background(), cfg.OpenAPISpec)
+		if err != nil {
+			logger.Error("failed to load OpenAPI spec", "error", err.Error())
+			os.Exit(1)
+		}
+
+		generated := openapi.NewGenerator(doc, restClient, openapi.Filter{
+			Include: cfg.OpenAPIInclude,
+			Exclude: cfg.OpenAPIExclude,
+			Renames: cfg.OpenAPIRenames,
+			Prefix:  cfg.OpenAPIToolPrefix,
+		}, logger).Register(context.Background(), toolRegistry)
+
+		logger.Info("Registered OpenAPI tools", "spec", cfg.OpenAPISpec, "tool_count", generated)
+	}
 
 	logger.Info("Registry tools", "tool_count", len(toolRegistry.ListTools()))
 
-	mcpServer := mcp.NewServer(toolRegistry, logger)
+	metadata, err := serverMetadata(cfg)
+	if err != nil {
+		logger.Error("failed to load server metadata", "error", err.Error())
+		os.Exit(1)
+	}
+
+	mcpServer := mcp.NewServer(toolRegistry, mcp.Options{
+		Metadata:        metadata,
+		ToolCallTimeout: cfg.ToolCallTimeout,
+		ErrorHandler:    toolerr.Map,
+	}, logger)
 
-	if err := mcpServer.Start(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := mcpServer.Start(ctx); err != nil {
 		logger.Error("Server error", "error", err.Error())
 	}
 }
+
+func serverMetadata(cfg *config.Config) (mcp.ServerMetadata, error) {
+	instructions := cfg.ServerInstructions
+	if cfg.ServerInstructionsFile != "" {
+		bs, err := os.ReadFile(cfg.ServerInstructionsFile)
+		if err != nil {
+			return mcp.ServerMetadata{}, fmt.Errorf("failed to read instructions file: %w", err)
+		}
+		instructions = string(bs)
+	}
+
+	icons := make([]mcp.Icon, 0, len(cfg.ServerIcons))
+	for _, src := range cfg.ServerIcons {
+		icons = append(icons, mcp.Icon{
+			Src:      src,
+			MimeType: mime.TypeByExtension(path.Ext(src)),
+		})
+	}
+
+	return mcp.ServerMetadata{
+		Title:        cfg.ServerTitle,
+		Description:  cfg.ServerDescription,
+		WebsiteURL:   cfg.ServerWebsiteURL,
+		Icons:        icons,
+		Instructions: instructions,
+	}, nil
+}
+
+func rateLimitConfig(cfg *config.Config) client.RateLimitConfig {
+	groups := make(map[string]client.RateLimit, len(cfg.RateLimits))
+	for group, perSecond := range cfg.RateLimits {
+		burst, ok := cfg.RateLimitBursts[group]
+		if !ok {
+			burst = cfg.RateLimitDefaultBurst
+		}
+		groups[group] = client.RateLimit{PerSecond: perSecond, Burst: burst}
+	}
+
+	return client.RateLimitConfig{
+		Default: client.RateLimit{PerSecond: cfg.RateLimitDefault, Burst: cfg.RateLimitDefaultBurst},
+		Groups:  groups,
+	}
+}
+
+func newDisplay(cfg *config.Config) (*money.Display, error) {
+	rates, err := money.LoadRates(cfg.ExchangeRatesFile, cfg.StoreCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	display, err := money.NewDisplay(cfg.StoreCurrency, rates)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.DisplayCurrency != "" {
+		if err := display.SetCurrency(cfg.DisplayCurrency); err != nil {
+			return nil, fmt.Errorf("DISPLAY_CURRENCY: %w", err)
+		}
+	}
+	return display, nil
+}
+
+func wishlistPath(cfg *config.Config) (string, error) {
+	if cfg.WishlistFile != "" {
+		return cfg.WishlistFile, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cartopher-copilot", "wishlist.json"), nil
+}
+
+func newBackend(cfg *config.Config, restClient *client.RestClient) (backend.Backend, error) {
+	switch cfg.Backend {
+	case "", "rest":
+		return rest.New(restClient), nil
+	case "memory":
+		return memory.Load(cfg.MemoryFixture)
+	default:
+		return nil, fmt.Errorf("unknown backend %q", cfg.Backend)
+	}
+}